@@ -3,67 +3,143 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/tomeryakir/gdau/apidiff"
+	"github.com/tomeryakir/gdau/changelog"
+	"github.com/tomeryakir/gdau/config"
 	git "github.com/tomeryakir/gdau/gitutils"
 	dep "github.com/tomeryakir/gdau/parsers"
 	"github.com/tomeryakir/gdau/report"
+	"github.com/tomeryakir/gdau/semver"
 	"github.com/tomeryakir/gdau/utils"
+	"github.com/tomeryakir/gdau/verify"
 )
 
 func main() {
 	var depsPath string
+	var repoPath string
 	var gopath string
 	var tipe string
+	var policy string
+	var preReleases string
+	var depPolicy string
+	var trackHead bool
+	var verifyMode string
+	var verifyAll bool
+	var jobs int
+	var lsRemote bool
+	var cacheDir string
+	var fetchGopath bool
+	var backend string
+	var details bool
 	var updateFile bool
 	var debug bool
 
 	flag.StringVar(&depsPath, "path", "", "path to dependency file")
-	flag.StringVar(&gopath, "gopath", "", "path to packages root")
-	flag.StringVar(&tipe, "deptype", "gpm", "type of dependency file (can be gpm, dep, module)")
+	flag.StringVar(&repoPath, "repo", "", "path in a git repository; every dependency file of the repository is analyzed (instead of --path)")
+	flag.StringVar(&gopath, "gopath", "", "path to packages root; only read, e.g. for the remotes of the packages in it, unless --fetchGopath is given")
+	flag.StringVar(&cacheDir, "cache", "", "directory of the mirrors the dependencies are fetched into and analyzed in (default: gdau/mirrors in the user cache directory)")
+	flag.StringVar(&backend, "vcs", git.Git, fmt.Sprintf("how the mirrors are read (can be %s); %s reads them in the tool itself, fetching them still runs git", strings.Join(git.Backends(), ", "), git.InProcess))
+	flag.BoolVar(&fetchGopath, "fetchGopath", false, "fetch the packages into --gopath as well: go get the missing ones, and check out and pull the default branch of the others")
+	flag.StringVar(&tipe, "deptype", "", fmt.Sprintf("type of dependency file (can be %s); by default it's detected from the file name and content", strings.Join(dep.FormatNames(), ", ")))
+	flag.StringVar(&policy, "policy", semver.PolicyMajor, fmt.Sprintf("how far updates may go from the current version (can be %s)", strings.Join(semver.Policies(), ", ")))
+	flag.StringVar(&preReleases, "prereleases", semver.PreReleaseStable, fmt.Sprintf("whether updates may move to pre-release versions (can be %s; %s only when the current version is a pre-release)", strings.Join(semver.PreReleaseModes(), ", "), semver.PreReleaseIfPinned))
+	flag.StringVar(&depPolicy, "depPolicy", "", "per dependency policies overriding --policy, e.g. github.com/pkg/errors=patch,github.com/acme/lib=minor")
+	flag.BoolVar(&trackHead, "trackHead", false, "compare dependencies pinned to a commit with the latest commit instead of the newest release tag")
+	flag.StringVar(&verifyMode, "verify", "", fmt.Sprintf("verify the updates by running go on a copy of the project with them (can be %s); only verified updates are written by --updateFile", strings.Join(verify.Modes(), ", ")))
+	flag.BoolVar(&verifyAll, "verifyAll", false, "verify all the updates together instead of one at a time, bisecting them to find the ones that break the project")
+	flag.IntVar(&jobs, "jobs", 1, "how many repositories are fetched and analyzed at a time")
+	flag.BoolVar(&lsRemote, "lsRemote", false, "check for newer versions with git ls-remote instead of fetching the packages; commit pins compared with releases are still fetched")
+	flag.BoolVar(&details, "details", false, "with --lsRemote, fetch the outdated packages for the diff summary, changes and API comparison")
 	flag.BoolVar(&debug, "debug", false, "turn on debug")
 	flag.BoolVar(&updateFile, "updateFile", false, "update the dependency file")
 	flag.Parse()
 
 	logger := utils.NewLogger(debug)
 
-	if depsPath == "" {
+	if depsPath == "" && repoPath == "" {
 		flag.Usage()
 		panic("dependency path wasn't specified")
 	}
-	if gopath == "" {
+	if fetchGopath && gopath == "" {
 		flag.Usage()
-		panic("Gopath wasn't specified")
+		panic("--fetchGopath needs --gopath")
+	}
+	if cacheDir == "" {
+		var err error
+		if cacheDir, err = git.DefaultCacheDir(); err != nil {
+			flag.Usage()
+			panic(err.Error())
+		}
+	}
+	if !git.IsBackend(backend) {
+		flag.Usage()
+		panic(fmt.Sprintf("unsupported VCS backend %s", backend))
+	}
+	if !semver.IsPolicy(policy) {
+		flag.Usage()
+		panic(fmt.Sprintf("unsupported policy %s", policy))
+	}
+	if !semver.IsPreReleaseMode(preReleases) {
+		flag.Usage()
+		panic(fmt.Sprintf("unsupported pre-release handling %s", preReleases))
+	}
+	if verifyMode != "" && !verify.IsMode(verifyMode) {
+		flag.Usage()
+		panic(fmt.Sprintf("unsupported verification %s", verifyMode))
+	}
+	if jobs < 1 {
+		flag.Usage()
+		panic(fmt.Sprintf("invalid number of jobs %d", jobs))
+	}
+	depPolicies, err := parseDepPolicies(depPolicy)
+	if err != nil {
+		flag.Usage()
+		panic(err.Error())
+	}
+	s := &settings{policy, preReleases, depPolicies, trackHead, verifyMode, verifyAll, jobs, lsRemote, details, updateFile, cacheDir, backend, fetchGopath, make(map[string]bool)}
+	flag.Visit(func(f *flag.Flag) {
+		s.explicit[f.Name] = true
+	})
+	if repoPath != "" {
+		analyzeRepository(repoPath, gopath, tipe, s, updateFile, logger)
+		return
 	}
 	gitRoot := git.GetGitRoot(depsPath, logger)
 	logger.LogDebug("got git root %s", gitRoot)
 
-	var parser dep.Parser
-	switch tipe {
-	case "gpm":
-		parser = dep.NewGPMParser(gitRoot, depsPath, logger)
-	case "dep":
-		parser = dep.NewGopkgParser(gitRoot, depsPath, logger)
-	default:
-		logger.PanicWithMessage("unsupported dependency format %s", tipe)
+	parser, err := dep.NewParser(tipe, gitRoot, depsPath, logger)
+	if err != nil {
+		logger.PanicWithMessage("%v", err)
 	}
 
 	entries, content, contentMap, entryMap := dep.ReadDependencyFile(parser)
 	logger.LogDebug("got entries %+v", entries)
+	configure(entries, depsPath, gitRoot, s, logger)
 
-	analyzeEntries(entries, gopath, logger)
+	analyzeEntries(entries, gopath, s, scanUsage(gitRoot, gopath, logger), logger)
+	file := &manifestFile{parser, entries, content, contentMap, entryMap}
+	if s.verify != "" {
+		verifyEntries(file, tipe, gopath, s, logger)
+	}
 
-	err := report.GenerateReportFile(entries)
+	err = report.GenerateReportFile(entries)
 	if err != nil {
 		logger.PanicWithMessage("failed to generate the report file. error: %v", err)
 	}
 	report.OpenReportFile()
 
 	if updateFile {
-		dep.UpdateDependencyFile(parser, entries, content, contentMap, entryMap)
+		dep.UpdateDependencyFile(file.parser, file.entries, file.content, file.contentMap, file.entryMap)
 	}
 
 }
@@ -79,47 +155,66 @@ func runCommand(cmd, cmdDir, cmdArgs string, logger *utils.Logger) (string, erro
 	return string(out), err
 }
 
-func analyzeEntry(entry *dep.Entry, gopath string, logger *utils.Logger) {
+// analyzeEntry - compare an entry with its upstream, read through the VCS of its fetched repository
+func analyzeEntry(entry *dep.Entry, vcs git.VCS, logger *utils.Logger) {
 	logger.LogInfo("analyzing package %s", entry.Path)
-	packagePath := path.Join(gopath, "src", entry.Path)
-	if utils.DirExists(packagePath) && entry.GitRemote == "" {
-		url, err := git.GetGitRemoteURL(packagePath, logger)
+	if entry.ReplacePath != "" {
+		logger.LogInfo("package %s is replaced by %s", entry.Path, entry.ReplacePath)
+	}
+	remote, err := vcs.RemoteURL()
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return
+	}
+	// ssh remotes can't be opened in a browser
+	entry.RemoteURL = git.BrowseURL(remote)
+	entry.ReleasesURL = fmt.Sprintf("%s/releases", entry.RemoteURL)
+	if entry.GitType == dep.Tag && entry.VersionRange == "" {
+		entry.CommitVersion = tagOf(entry.CommitVersion, func(name string) bool { return git.IsTag(vcs, name) })
+	}
+	if entry.GitType == dep.Tag && !git.IsTag(vcs, entry.CommitVersion) && git.IsBranch(vcs, entry.CommitVersion) {
+		// a version that names a branch, e.g. master
+		entry.GitType = dep.Branch
+		entry.Branch = entry.CommitVersion
+	}
+	if entry.VersionRange != "" && entry.CommitVersion == entry.VersionRange {
+		tags, _, err := git.GetReleaseTags(vcs, nil, tagPrefix(entry), logger)
 		if err != nil {
 			entry.IsProblem = true
 			entry.Summary = err.Error()
 			return
 		}
-		entry.RemoteURL = url
+		lockedRelease(entry, tags, func(tag string) string {
+			commit, err := vcs.ResolveRef(tag)
+			if err != nil {
+				return ""
+			}
+			return commit.Hash
+		})
 	}
-	if !utils.DirExists(packagePath) {
-		if err := git.Goget(gopath, entry.Path, packagePath, entry.GitRemote, logger); err != nil {
-			entry.Summary = err.Error()
-			entry.IsProblem = true
-			return
-		}
-	} else {
-		if err := git.AddRemote(entry.Path, entry.GitRemote, packagePath, logger); err != nil {
-			entry.Summary = err.Error()
-			entry.IsProblem = true
-			return
-		}
-		git.Gitpull(packagePath, logger)
+	if entry.Branch != "" {
+		analyzeBranch(entry, vcs, logger)
+		return
+	}
+	if entry.GitType == dep.Commit && !entry.TrackHead && analyzeCommitReleases(entry, vcs, logger) {
+		return
 	}
-
-	entry.ReleasesURL = fmt.Sprintf("%s/releases", strings.TrimSuffix(entry.RemoteURL, ".git"))
 	if entry.GitType == dep.Commit {
 		// get commits
-		commit, dateSummary, err := git.GetLatestGitCommit(packagePath, logger)
+		latest, err := vcs.LatestCommit()
 		if err != nil {
 			entry.IsProblem = true
 			entry.Summary = err.Error()
 			return
 		}
-		entry.NewCommitDateSummary = dateSummary
-		entry.NewCommitVersion = commit
-		if entry.CommitVersion != entry.NewCommitVersion {
+		entry.NewCommitDateSummary = git.DateSummary(latest.Time)
+		entry.NewCommitVersion = latest.Hash
+		entry.NewRevision = latest.Hash
+		if !sameCommit(entry.CommitVersion, entry.NewCommitVersion) {
 			entry.IsUpdated = false
-			summary, err := git.GetCommitDiffSummary(packagePath, entry.CommitVersion, commit, logger)
+			entry.NewCommitTime = latest.Time
+			summary, err := vcs.DiffStats(entry.CommitVersion, latest.Hash)
 			if err != nil {
 				entry.IsProblem = true
 				entry.Summary = err.Error()
@@ -130,49 +225,1166 @@ func analyzeEntry(entry *dep.Entry, gopath string, logger *utils.Logger) {
 		}
 	} else {
 		// tags or branches
-		oldcommit, err := git.GetCommitByTag(packagePath, entry.CommitVersion, logger)
+		old, err := vcs.ResolveRef(entry.CommitVersion)
 		if err != nil {
 			entry.IsProblem = true
 			entry.Summary = err.Error()
 			return
 		}
-		commit, tag, dateSummary, err := git.GetLatestGitCommitByTag(packagePath, logger)
+		tags, rejected, err := git.GetReleaseTags(vcs, entry.ExcludedVersions, tagPrefix(entry), logger)
+		tag := pickRelease(entry, tags, rejected, err, vcs, logger)
+		if tag == nil {
+			return
+		}
+		commit, err := vcs.ResolveRef(tag.Name)
 		if err != nil {
 			entry.IsProblem = true
-			entry.Summary = err.Error()
+			entry.Summary = fmt.Sprintf("failed to get commit for tag %s of package %s", tag.Name, entry.RepoPath())
 			return
 		}
-		entry.NewCommitDateSummary = dateSummary
-		entry.NewCommitVersion = tag
-		if entry.CommitVersion != entry.NewCommitVersion {
+		entry.NewCommitDateSummary = tag.DateSummary()
+		entry.NewCommitVersion = tag.Name
+		entry.NewRevision = commit.Hash
+		// the pinned version may be a commit that the latest tag points at
+		if entry.CommitVersion != entry.NewCommitVersion && !sameCommit(old.Hash, commit.Hash) {
 			entry.IsUpdated = false
-			summary, err := git.GetCommitDiffSummary(packagePath, oldcommit, commit, logger)
+			entry.NewCommitTime = commit.Time
+			summary, err := vcs.DiffStats(old.Hash, commit.Hash)
 			if err != nil {
 				entry.IsProblem = true
 				entry.Summary = err.Error()
 				return
 			}
 			entry.Summary = summary
-			entry.DiffURL = fmt.Sprintf("%s/compare/%s...%s", entry.RemoteURL, oldcommit, commit)
+			entry.DiffURL = fmt.Sprintf("%s/compare/%s...%s", entry.RemoteURL, old.Hash, commit.Hash)
+		}
+	}
+}
+
+// fetchedRepos - what a worker fetched in this run: the mirrors with the VCS they're read with, and the GOPATH copies
+type fetchedRepos struct {
+	mirrors map[string]git.VCS
+	gopath  map[string]bool
+}
+
+func newFetchedRepos() *fetchedRepos {
+	return &fetchedRepos{make(map[string]git.VCS), make(map[string]bool)}
+}
+
+// close - release what the VCS of the mirrors hold open, e.g. the pack files of the in-process backend
+func (f *fetchedRepos) close() {
+	for _, vcs := range f.mirrors {
+		if closer, ok := vcs.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+// openEntry - fetch the repository of an entry and return the VCS it's read with
+func openEntry(entry *dep.Entry, gopath string, s *settings, fetched *fetchedRepos, logger *utils.Logger) (git.VCS, error) {
+	remote, err := remoteOf(entry, gopath, logger)
+	if err != nil {
+		return nil, err
+	}
+	return fetchPackage(entry, remote, gopath, s, fetched, logger)
+}
+
+// fetchPackage - fetch the remote of an entry into its mirror in the cache, once per run, and set the entry's RepoDir to the mirror.
+// Returns the VCS the mirror is read with, as --vcs selects.
+// The GOPATH copy of the package is only fetched (go get, or a pull of its default branch) when --fetchGopath asks for it.
+func fetchPackage(entry *dep.Entry, remote, gopath string, s *settings, fetched *fetchedRepos, logger *utils.Logger) (git.VCS, error) {
+	mirror := git.MirrorPath(s.cacheDir, remote)
+	vcs, ok := fetched.mirrors[mirror]
+	if ok {
+		logger.LogDebug("%s was already fetched", mirror)
+	} else {
+		vcs = git.NewVCS(s.vcs, mirror, remote, logger)
+		if err := vcs.Fetch(); err != nil {
+			return nil, err
+		}
+		fetched.mirrors[mirror] = vcs
+	}
+	entry.RepoDir = mirror
+	if !s.fetchGopath {
+		return vcs, nil
+	}
+	packagePath := path.Join(gopath, "src", entry.RepoPath())
+	if fetched.gopath[packagePath] {
+		return vcs, nil
+	}
+	if !utils.DirExists(packagePath) {
+		if err := git.Goget(gopath, entry.RepoPath(), packagePath, entry.GitRemote, logger); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := git.AddRemote(entry.RepoPath(), entry.GitRemote, packagePath, logger); err != nil {
+			return nil, err
+		}
+		git.Gitpull(packagePath, logger)
+	}
+	fetched.gopath[packagePath] = true
+	return vcs, nil
+}
+
+// pickRelease - the release tag an entry pinned to a tag should move to, out of the tags read for it; vcs is nil when the tags were listed remotely.
+// Returns nil when there's none; the entry's summary says why.
+func pickRelease(entry *dep.Entry, tags []*git.ReleaseTag, rejected []git.RejectedTag, err error, vcs git.VCS, logger *utils.Logger) *git.ReleaseTag {
+	for _, r := range rejected {
+		entry.RejectedVersions = append(entry.RejectedVersions, fmt.Sprintf("%s (%s)", r.Tag, r.Reason))
+	}
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return nil
+	}
+	if tags = filterTags(entry, tags); len(tags) == 0 {
+		entry.IsProblem = true
+		entry.Summary = fmt.Sprintf("none of the release tags of package %s are allowed", entry.RepoPath())
+		return nil
+	}
+	// other major versions of go modules are only reported
+	entry.LatestVersion = tags[len(tags)-1].Name
+	if tags = moduleTags(entry, tags, vcs); len(tags) == 0 {
+		entry.Summary = fmt.Sprintf("no release of package %s can be required on its module path", entry.RepoPath())
+		return nil
+	}
+	tag := latestAllowedTag(entry, tags, logger)
+	if tag == nil {
+		entry.Summary = fmt.Sprintf("no release is allowed by the %s policy", entry.Policy)
+	}
+	return tag
+}
+
+// remoteOf - the remote an entry is fetched from: its configured remote, the origin of its GOPATH copy, or what its import path resolves to
+func remoteOf(entry *dep.Entry, gopath string, logger *utils.Logger) (string, error) {
+	if entry.GitRemote != "" {
+		return entry.GitRemote, nil
+	}
+	if packagePath := path.Join(gopath, "src", entry.RepoPath()); gopath != "" && utils.DirExists(packagePath) {
+		return git.GetGitRemoteURL(packagePath, logger)
+	}
+	return git.RemoteForImportPath(entry.RepoPath(), logger)
+}
+
+// analyzeRemote - compare an entry with the refs its remote advertises (git ls-remote), without fetching it.
+// Outdated entries are only fetched when s asks for details or verification, which need their history, or when the file update
+// needs the time of the new commit: updates to a commit (go.mod pseudo-versions) and files that record it (vendor.json).
+// Returns false for commit pins that are compared with releases: finding the releases that contain a commit takes the history,
+// so those are left to analyzeEntry.
+func analyzeRemote(entry *dep.Entry, gopath string, s *settings, fetched *fetchedRepos, usage *apidiff.Usage, logger *utils.Logger) bool {
+	if entry.GitType == dep.Commit && entry.Branch == "" && !entry.TrackHead {
+		return false
+	}
+	logger.LogInfo("checking package %s with ls-remote", entry.Path)
+	remote, err := remoteOf(entry, gopath, logger)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	refs, err := git.ListRemote(remote, logger)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	entry.RemoteURL = git.BrowseURL(remote)
+	entry.ReleasesURL = fmt.Sprintf("%s/releases", entry.RemoteURL)
+	if entry.GitType == dep.Tag && entry.VersionRange == "" {
+		entry.CommitVersion = tagOf(entry.CommitVersion, func(name string) bool { _, ok := refs.Tags[name]; return ok })
+	}
+	if _, isTag := refs.Tags[entry.CommitVersion]; entry.GitType == dep.Tag && !isTag && refs.Branches[entry.CommitVersion] != "" {
+		// a version that names a branch, e.g. master
+		entry.GitType = dep.Branch
+		entry.Branch = entry.CommitVersion
+	}
+	oldcommit := entry.CommitVersion
+	switch {
+	case entry.Branch != "":
+		head, ok := refs.Branches[entry.Branch]
+		if !ok {
+			entry.IsProblem = true
+			entry.Summary = fmt.Sprintf("branch %s wasn't found in %s", entry.Branch, remote)
+			return true
+		}
+		entry.NewRevision = head
+		if entry.GitType == dep.Branch {
+			entry.NewCommitVersion = entry.Branch
+			entry.Summary = fmt.Sprintf("follows branch %s, now at %s", entry.Branch, head)
+			return true
+		}
+		entry.NewCommitVersion = head
+		if commit, ok := refs.Tags[entry.CommitVersion]; ok && entry.GitType == dep.Tag {
+			// a release that follows a branch (a branch rule) moves to a commit of it
+			oldcommit = commit
+			if !sameCommit(oldcommit, head) {
+				entry.GitType = dep.Commit
+			}
+		}
+	case entry.GitType == dep.Commit:
+		entry.NewCommitVersion = refs.Head
+		entry.NewRevision = refs.Head
+	default:
+		if entry.VersionRange != "" && entry.CommitVersion == entry.VersionRange {
+			tags, _, err := refs.ReleaseTags(nil, tagPrefix(entry), logger)
+			if err != nil {
+				entry.IsProblem = true
+				entry.Summary = err.Error()
+				return true
+			}
+			lockedRelease(entry, tags, func(tag string) string { return refs.Tags[tag] })
+			if entry.GitType == dep.Commit {
+				// locked between releases, left to analyzeEntry like the other commit pins
+				return false
+			}
+		}
+		var ok bool
+		if oldcommit, ok = refs.Tags[entry.CommitVersion]; !ok {
+			entry.IsProblem = true
+			entry.Summary = fmt.Sprintf("tag %s wasn't found in %s", entry.CommitVersion, remote)
+			return true
+		}
+		tags, rejected, err := refs.ReleaseTags(entry.ExcludedVersions, tagPrefix(entry), logger)
+		tag := pickRelease(entry, tags, rejected, err, nil, logger)
+		if tag == nil {
+			return true
+		}
+		entry.NewCommitVersion = tag.Name
+		entry.NewRevision = refs.Tags[tag.Name]
+		if entry.CommitVersion == entry.NewCommitVersion {
+			return true
+		}
+	}
+	if sameCommit(oldcommit, entry.NewRevision) {
+		return true
+	}
+	entry.IsUpdated = false
+	entry.DiffURL = fmt.Sprintf("%s/compare/%s...%s", entry.RemoteURL, oldcommit, entry.NewRevision)
+	entry.Summary = fmt.Sprintf("outdated, the remote is at %s (--details fetches the changes)", shortCommit(entry.NewRevision))
+	needsTime := s.updateFile && (entry.GitType == dep.Commit || entry.RecordsCommitTime)
+	if !s.details && s.verify == "" && !needsTime {
+		return true
+	}
+	vcs, err := fetchPackage(entry, remote, gopath, s, fetched, logger)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	commit, err := vcs.ResolveRef(entry.NewRevision)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	entry.NewCommitTime = commit.Time
+	entry.NewCommitDateSummary = git.DateSummary(commit.Time)
+	if entry.Branch != "" {
+		if entry.CommitsBehind, err = git.CountCommits(vcs, oldcommit, entry.NewRevision); err != nil {
+			entry.IsProblem = true
+			entry.Summary = err.Error()
+			return true
+		}
+	}
+	if !s.details {
+		return true
+	}
+	summary, err := vcs.DiffStats(oldcommit, entry.NewRevision)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	entry.Summary = summary
+	collectChanges(entry, vcs, logger)
+	compareAPI(entry, vcs, usage, logger)
+	return true
+}
+
+// analyzeBranch - compare an entry that follows a branch with the head of that branch
+func analyzeBranch(entry *dep.Entry, vcs git.VCS, logger *utils.Logger) {
+	commit, err := git.GetBranchHead(vcs, entry.Branch)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return
+	}
+	head := commit.Hash
+	entry.NewCommitDateSummary = git.DateSummary(commit.Time)
+	entry.NewRevision = head
+	if entry.GitType == dep.Branch {
+		// the dependency file names the branch itself, so it always gets the head
+		entry.NewCommitVersion = entry.Branch
+		entry.Summary = fmt.Sprintf("follows branch %s, now at %s", entry.Branch, head)
+		return
+	}
+	entry.NewCommitVersion = head
+	old, err := vcs.ResolveRef(entry.CommitVersion)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return
+	}
+	if sameCommit(old.Hash, head) {
+		return
+	}
+	entry.IsUpdated = false
+	if entry.GitType == dep.Tag {
+		// a release that follows a branch (a branch rule) moves to a commit of it
+		entry.GitType = dep.Commit
+	}
+	if entry.CommitsBehind, err = git.CountCommits(vcs, entry.CommitVersion, head); err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return
+	}
+	entry.NewCommitTime = commit.Time
+	summary, err := vcs.DiffStats(entry.CommitVersion, head)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return
+	}
+	entry.Summary = summary
+	entry.DiffURL = fmt.Sprintf("%s/compare/%s...%s", entry.RemoteURL, entry.CommitVersion, head)
+}
+
+// analyzeCommitReleases - compare a commit pin with the newest release tag that contains it.
+// Returns false when the repository has no release tags, so the commit is compared with HEAD instead.
+func analyzeCommitReleases(entry *dep.Entry, vcs git.VCS, logger *utils.Logger) bool {
+	if described, err := git.DescribeCommit(vcs, entry.CommitVersion); err == nil {
+		entry.Describe = described
+	}
+	tags, rejected, err := git.GetReleaseTags(vcs, entry.ExcludedVersions, tagPrefix(entry), logger)
+	if err != nil {
+		logger.LogDebug("comparing %s with HEAD: %v", entry.Path, err)
+		return false
+	}
+	for _, r := range rejected {
+		entry.RejectedVersions = append(entry.RejectedVersions, fmt.Sprintf("%s (%s)", r.Tag, r.Reason))
+	}
+	containing, err := git.GetTagsContaining(vcs, entry.CommitVersion)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	tags = filterTags(entry, tags)
+	if len(tags) > 0 {
+		entry.LatestVersion = tags[len(tags)-1].Name
+	}
+	newer := make([]*git.ReleaseTag, 0, len(tags))
+	for _, tag := range tags {
+		if containing[tag.Name] {
+			newer = append(newer, tag)
+		}
+	}
+	if len(newer) == 0 {
+		entry.Summary = fmt.Sprintf("commit %s isn't in a release yet", shortCommit(entry.CommitVersion))
+		return true
+	}
+	if newer = moduleTags(entry, newer, vcs); len(newer) == 0 {
+		entry.Summary = fmt.Sprintf("commit %s isn't in a release that can be required on its module path yet", shortCommit(entry.CommitVersion))
+		return true
+	}
+	tag := latestAllowedTag(entry, newer, logger)
+	if tag == nil {
+		entry.Summary = fmt.Sprintf("no release is allowed by the %s policy", entry.Policy)
+		return true
+	}
+	commit, err := vcs.ResolveRef(tag.Name)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	entry.NewCommitVersion = tag.Name
+	entry.NewRevision = commit.Hash
+	entry.NewCommitDateSummary = tag.DateSummary()
+	if sameCommit(entry.CommitVersion, commit.Hash) {
+		return true
+	}
+	entry.IsUpdated = false
+	// the update moves the entry from the commit to the release tag
+	entry.GitType = dep.Tag
+	entry.NewCommitTime = commit.Time
+	summary, err := vcs.DiffStats(entry.CommitVersion, commit.Hash)
+	if err != nil {
+		entry.IsProblem = true
+		entry.Summary = err.Error()
+		return true
+	}
+	from := shortCommit(entry.CommitVersion)
+	if entry.Describe != "" {
+		from = fmt.Sprintf("%s (≈ %s)", from, entry.Describe)
+	}
+	entry.Summary = fmt.Sprintf("move from commit %s to tag %s; %s", from, tag.Name, strings.TrimSpace(summary))
+	entry.DiffURL = fmt.Sprintf("%s/compare/%s...%s", entry.RemoteURL, entry.CommitVersion, commit.Hash)
+	return true
+}
+
+// shortCommit - the abbreviated form of a commit hash
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// latestAllowedTag - the highest release that the entry's update policy allows, or nil if there's none
+func latestAllowedTag(entry *dep.Entry, tags []*git.ReleaseTag, logger *utils.Logger) *git.ReleaseTag {
+	if entry.Rule != nil && entry.Rule.Pin != "" {
+		// only the pinned tag is left, and a pin wins over the policy
+		return tags[len(tags)-1]
+	}
+	current := currentVersion(entry)
+	if current == nil {
+		logger.LogInfo("%s of %s isn't a semantic version, the %s policy doesn't apply", entry.CommitVersion, entry.Path, entry.Policy)
+		return tags[len(tags)-1]
+	}
+	for i := len(tags) - 1; i >= 0; i-- {
+		if tags[i].Version.Compare(current) < 0 {
+			break
+		}
+		if semver.WithinPolicy(entry.Policy, current, tags[i].Version) {
+			return tags[i]
+		}
+	}
+	return nil
+}
+
+// lockedRelease - move an entry constrained by a version range (glide.yaml) from the range to the newest release in it that its locked revision is at;
+// an entry locked between releases becomes a commit pin. commitOf is the commit a tag points at.
+func lockedRelease(entry *dep.Entry, tags []*git.ReleaseTag, commitOf func(tag string) string) {
+	for i := len(tags) - 1; i >= 0; i-- {
+		if inVersionRange(entry, tags[i]) && sameCommit(commitOf(tags[i].Name), entry.LockedRevision) {
+			entry.CommitVersion = tags[i].Name
+			return
+		}
+	}
+	entry.GitType = dep.Commit
+	entry.CommitVersion = entry.LockedRevision
+}
+
+// tagOf - the tag a release version names; glide.yaml and Gopkg.toml versions may leave out the v of the tag (1.2.0 for v1.2.0), or add one
+func tagOf(version string, isTag func(name string) bool) string {
+	if isTag(version) {
+		return version
+	}
+	other := "v" + version
+	if strings.HasPrefix(version, "v") {
+		other = strings.TrimPrefix(version, "v")
+	}
+	if isTag(other) {
+		return other
+	}
+	return version
+}
+
+// inVersionRange - whether a release satisfies the version range of an entry, if it has one
+func inVersionRange(entry *dep.Entry, tag *git.ReleaseTag) bool {
+	if entry.VersionRange == "" {
+		return true
+	}
+	ok, err := semver.Satisfies(entry.VersionRange, strings.TrimPrefix(tag.Name, tagPrefix(entry)))
+	return err == nil && ok
+}
+
+// tagPrefix - the prefix the release tags of an entry start with
+func tagPrefix(entry *dep.Entry) string {
+	if entry.Rule == nil {
+		return ""
+	}
+	return entry.Rule.TagPrefix
+}
+
+// currentVersion - the version the entry is at, or nil if it isn't a semantic version; a commit counts as the tag before it
+func currentVersion(entry *dep.Entry) *semver.Version {
+	version := entry.CommitVersion
+	if entry.Describe != "" {
+		// only commit pins are described
+		version = entry.Describe
+	}
+	if current, err := semver.Parse(strings.TrimPrefix(version, tagPrefix(entry))); err == nil {
+		return current
+	}
+	return nil
+}
+
+// maxAPIChanges - the most API changes listed per entry
+const maxAPIChanges = 50
+
+// compareAPI - compare the exported Go API of the current and the new version of an outdated entry, and find where our code uses what changed
+func compareAPI(entry *dep.Entry, vcs git.VCS, usage *apidiff.Usage, logger *utils.Logger) {
+	oldFiles, err := git.GetGoFiles(vcs, entry.CommitVersion)
+	if err != nil {
+		logger.LogInfo("no API comparison for %s: %v", entry.Path, err)
+		return
+	}
+	newFiles, err := git.GetGoFiles(vcs, entry.NewRevision)
+	if err != nil {
+		logger.LogInfo("no API comparison for %s: %v", entry.Path, err)
+		return
+	}
+	// keyed by the import path our code uses, which differs from the fetched path for replaced modules and forks
+	changes := apidiff.Diff(apidiff.Extract(entry.Path, oldFiles), apidiff.Extract(entry.Path, newFiles))
+	entry.AffectedCode = usage.Affected(changes)
+	entry.APIStatus = "compatible"
+	if apidiff.IsBreaking(changes) {
+		entry.APIStatus = "breaking"
+	}
+	// breaking changes first
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Breaking && !changes[j].Breaking })
+	for i, change := range changes {
+		if i == maxAPIChanges {
+			entry.APIChanges = append(entry.APIChanges, fmt.Sprintf("and %d more", len(changes)-maxAPIChanges))
+			break
 		}
+		entry.APIChanges = append(entry.APIChanges, change.String())
 	}
 }
 
-func analyzeEntries(entries []*dep.Entry, gopath string, logger *utils.Logger) {
-	srcPath := path.Join(gopath, "src")
-	if !utils.DirExists(srcPath) {
-		err := os.Mkdir(srcPath, 0777)
+// maxCommitLog - the most commit subjects listed per entry
+const maxCommitLog = 20
+
+// collectChanges - the commit log and the changelog excerpt between the current and the new version of an outdated entry
+func collectChanges(entry *dep.Entry, vcs git.VCS, logger *utils.Logger) {
+	var err error
+	if entry.CommitLog, entry.MoreCommits, err = git.GetCommitLog(vcs, entry.CommitVersion, entry.NewRevision, maxCommitLog); err != nil {
+		logger.LogInfo("no commit log for %s: %v", entry.Path, err)
+	}
+	from := currentVersion(entry)
+	if from == nil {
+		return
+	}
+	to, err := semver.Parse(strings.TrimPrefix(entry.NewCommitVersion, tagPrefix(entry)))
+	if err != nil {
+		// a commit, every section after the current version is new
+		to = nil
+	}
+	files, err := vcs.Files(entry.NewRevision, func(name string) bool {
+		return !strings.Contains(name, "/") && changelog.IsChangelog(name)
+	})
+	if err != nil {
+		logger.LogInfo("no changelog for %s: %v", entry.Path, err)
+		return
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return
+	}
+	// the first one, as git lists the files of the root
+	sort.Strings(names)
+	entry.Changelog = changelog.Between(string(files[names[0]]), from, to)
+}
+
+// moduleTags - for go.mod requirements, the tags that can be required on the module path: the major version the path implies,
+// and for paths without a major version suffix v2 and up as +incompatible versions, but only for tags without a go.mod.
+// vcs is nil when the tags were listed remotely, then their go.mod can't be checked and the +incompatible versions are left out.
+func moduleTags(entry *dep.Entry, tags []*git.ReleaseTag, vcs git.VCS) []*git.ReleaseTag {
+	if entry.ModuleMajor == 0 {
+		return tags
+	}
+	allowed := make([]*git.ReleaseTag, 0, len(tags))
+	for _, tag := range tags {
+		major := tag.Version.Major()
+		if major <= 1 && entry.ModuleMajor == 1 || major == entry.ModuleMajor {
+			allowed = append(allowed, tag)
+			continue
+		}
+		reason := fmt.Sprintf("major version %d needs another module path", major)
+		if major > 1 && entry.AllowsIncompatible {
+			if vcs == nil {
+				reason = "+incompatible version, its go.mod isn't checked without fetching"
+			} else if hasGoMod, err := git.HasFile(vcs, tag.Name, "go.mod"); err != nil {
+				reason = fmt.Sprintf("+incompatible version, failed to check its go.mod: %v", err)
+			} else if hasGoMod {
+				reason = fmt.Sprintf("has a go.mod, major version %d needs another module path", major)
+			} else {
+				allowed = append(allowed, tag)
+				continue
+			}
+		}
+		entry.RejectedVersions = append(entry.RejectedVersions, fmt.Sprintf("%s (%s)", tag.Name, reason))
+	}
+	return allowed
+}
+
+// filterTags - drop the tags the entry's pre-release handling, version range, pin or ceiling don't allow
+func filterTags(entry *dep.Entry, tags []*git.ReleaseTag) []*git.ReleaseTag {
+	allowPreReleases := semver.AllowsPreReleases(entry.PreReleases, currentVersion(entry), entry.ReleaseSuffixes)
+	allowed := make([]*git.ReleaseTag, 0, len(tags))
+	for _, tag := range tags {
+		reason := ""
+		switch {
+		case entry.Rule != nil && entry.Rule.Pin != "":
+			if tag.Name != entry.Rule.Pin {
+				reason = fmt.Sprintf("pinned to %s", entry.Rule.Pin)
+			}
+		case !tag.Version.IsRelease(entry.ReleaseSuffixes) && !allowPreReleases:
+			reason = "pre-release"
+		case !inVersionRange(entry, tag):
+			reason = fmt.Sprintf("outside the version range %s", entry.VersionRange)
+		case entry.Rule != nil && entry.Rule.Ceiling != "":
+			if ok, err := semver.WithinCeiling(entry.Rule.Ceiling, tag.Version); err != nil || !ok {
+				reason = fmt.Sprintf("above the ceiling %s", entry.Rule.Ceiling)
+			}
+		}
+		if reason != "" {
+			entry.RejectedVersions = append(entry.RejectedVersions, fmt.Sprintf("%s (%s)", tag.Name, reason))
+			continue
+		}
+		allowed = append(allowed, tag)
+	}
+	return allowed
+}
+
+// settings - the update settings given on the command line
+type settings struct {
+	policy      string
+	preReleases string
+	depPolicies map[string]string
+	trackHead   bool
+	verify      string
+	verifyAll   bool
+	jobs        int
+	lsRemote    bool
+	details     bool
+	// --updateFile, which may write commit times (go.mod pseudo-versions, vendor.json revisionTime)
+	updateFile  bool
+	cacheDir    string
+	vcs         string
+	fetchGopath bool
+	// flags that were given explicitly, these win over the project configuration
+	explicit map[string]bool
+}
+
+// configure - apply the command line settings and the project configuration (.gdau.yaml) to the entries of a dependency file.
+// --depPolicy wins over the configuration rules, which win over the global policy.
+func configure(entries []*dep.Entry, depPath, gitRoot string, s *settings, logger *utils.Logger) {
+	var cfg *config.Config
+	if configPath := config.Find(depPath, gitRoot); configPath != "" {
+		var err error
+		if cfg, err = config.Load(configPath); err != nil {
+			logger.PanicWithMessage("%v", err)
+		}
+		logger.LogInfo("using configuration %s", configPath)
+	}
+	policy, preReleases := s.policy, s.preReleases
+	if cfg != nil && cfg.Policy != "" && !s.explicit["policy"] {
+		policy = cfg.Policy
+	}
+	if cfg != nil && cfg.PreReleases != "" && !s.explicit["prereleases"] {
+		preReleases = cfg.PreReleases
+	}
+	for _, entry := range entries {
+		entry.Policy = policy
+		entry.PreReleases = preReleases
+		entry.TrackHead = s.trackHead
+		if cfg != nil {
+			entry.ReleaseSuffixes = cfg.ReleaseSuffixes
+		}
+	}
+	dep.ApplyConfig(entries, cfg)
+	for _, entry := range entries {
+		if p, ok := s.depPolicies[entry.Path]; ok {
+			entry.Policy = p
+		}
+	}
+}
+
+// parseDepPolicies - parse "path=policy,path=policy"
+func parseDepPolicies(s string) (map[string]string, error) {
+	depPolicies := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		tokens := strings.SplitN(item, "=", 2)
+		if len(tokens) != 2 || !semver.IsPolicy(tokens[1]) {
+			return nil, fmt.Errorf("invalid dependency policy %s, expected <path>=<%s>", item, strings.Join(semver.Policies(), "|"))
+		}
+		depPolicies[tokens[0]] = tokens[1]
+	}
+	return depPolicies, nil
+}
+
+// sameCommit - whether two commit hashes point at the same commit; go.mod pseudo-versions only carry 12 characters
+func sameCommit(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return a != "" && strings.HasPrefix(b, a)
+}
+
+// manifestFile - a dependency file as its parser read it, for writing the updates back
+type manifestFile struct {
+	parser     dep.Parser
+	entries    []*dep.Entry
+	content    string
+	contentMap map[string]string
+	entryMap   map[string]*dep.Entry
+}
+
+// analyzeRepository - analyze every dependency file of the repository, fetching and analyzing each shared upstream once
+func analyzeRepository(repoPath, gopath, tipe string, s *settings, updateFile bool, logger *utils.Logger) {
+	gitRoot := git.GetGitRootOfDir(repoPath, logger)
+	logger.LogDebug("got git root %s", gitRoot)
+	manifestPaths, err := dep.DiscoverManifests(gitRoot, logger)
+	if err != nil {
+		logger.PanicWithMessage("failed to search %s for dependency files. error: %v", gitRoot, err)
+	}
+	if len(manifestPaths) == 0 {
+		logger.PanicWithMessage("no dependency files were found in %s", gitRoot)
+	}
+
+	manifests := make([]*report.Manifest, 0)
+	files := make([]*manifestFile, 0)
+	all := make([]*dep.Entry, 0)
+	for _, manifestPath := range manifestPaths {
+		parser, err := dep.NewParser(tipe, gitRoot, manifestPath, logger)
+		if err != nil {
+			logger.LogInfo("skipping %s: %v", manifestPath, err)
+			continue
+		}
+		entries, content, contentMap, entryMap := dep.ReadDependencyFile(parser)
+		configure(entries, manifestPath, gitRoot, s, logger)
+		relPath, err := filepath.Rel(gitRoot, manifestPath)
 		if err != nil {
-			logger.PanicWithMessage("failed to create dir %s. error: %v", srcPath, err)
+			relPath = manifestPath
+		}
+		manifests = append(manifests, &report.Manifest{Path: relPath, Entries: entries})
+		files = append(files, &manifestFile{parser, entries, content, contentMap, entryMap})
+		all = append(all, entries...)
+	}
+
+	analyzeSharedEntries(all, gopath, s, scanUsage(gitRoot, gopath, logger), logger)
+	if s.verify != "" {
+		// every dependency file is a project of its own, built with its own versions
+		for _, f := range files {
+			verifyEntries(f, tipe, gopath, s, logger)
+		}
+	}
+
+	err = report.GenerateRepositoryReportFile(gitRoot, manifests)
+	if err != nil {
+		logger.PanicWithMessage("failed to generate the report file. error: %v", err)
+	}
+	report.OpenReportFile()
+
+	if updateFile {
+		for _, f := range files {
+			dep.UpdateDependencyFile(f.parser, f.entries, f.content, f.contentMap, f.entryMap)
 		}
 	}
+}
+
+// analysisKey - entries with the same key get the same analysis results
+func analysisKey(entry *dep.Entry) string {
+	return strings.Join([]string{entry.RepoPath(), entry.GitRemote, strconv.Itoa(int(entry.GitType)), strconv.Itoa(entry.ModuleMajor), entry.CommitVersion, entry.Policy, entry.PreReleases, strings.Join(entry.ReleaseSuffixes, ","), strconv.FormatBool(entry.TrackHead), strconv.FormatBool(entry.RecordsCommitTime), fmt.Sprint(entry.Rule), strings.Join(entry.ExcludedVersions, ",")}, "|")
+}
+
+// analyzeSharedEntries - analyze entries that several dependency files share only once
+func analyzeSharedEntries(entries []*dep.Entry, gopath string, s *settings, usage *apidiff.Usage, logger *utils.Logger) {
+	unique := make([]*dep.Entry, 0)
+	analyzed := make(map[string]*dep.Entry)
 	for _, entry := range entries {
 		if entry.IsSkipped {
 			continue
 		}
-		// not parallelising this for now as there may be multiple packages that use the same path
-		logger.LogDebug("analysing entry %v", *entry)
-		analyzeEntry(entry, gopath, logger)
-		logger.LogDebug("** package %s - data: %v", entry.Path, *entry)
+		if _, ok := analyzed[analysisKey(entry)]; !ok {
+			analyzed[analysisKey(entry)] = entry
+			unique = append(unique, entry)
+		}
+	}
+	analyzeEntries(unique, gopath, s, usage, logger)
+	for _, entry := range entries {
+		if from, ok := analyzed[analysisKey(entry)]; ok && from != entry && !entry.IsSkipped {
+			copyAnalysis(from, entry)
+		}
+	}
+}
+
+// copyAnalysis - copy the analysis results of an entry
+func copyAnalysis(from, to *dep.Entry) {
+	to.GitType = from.GitType
+	to.Branch = from.Branch
+	to.IsUpdated = from.IsUpdated
+	to.IsProblem = from.IsProblem
+	to.RemoteURL = from.RemoteURL
+	to.ReleasesURL = from.ReleasesURL
+	to.Describe = from.Describe
+	to.NewCommitVersion = from.NewCommitVersion
+	to.LatestVersion = from.LatestVersion
+	to.NewRevision = from.NewRevision
+	to.RepoDir = from.RepoDir
+	to.NewCommitDateSummary = from.NewCommitDateSummary
+	to.NewCommitTime = from.NewCommitTime
+	to.CommitsBehind = from.CommitsBehind
+	to.DiffURL = from.DiffURL
+	to.CommitLog = from.CommitLog
+	to.MoreCommits = from.MoreCommits
+	to.Changelog = from.Changelog
+	to.APIStatus = from.APIStatus
+	to.APIChanges = from.APIChanges
+	to.AffectedCode = from.AffectedCode
+	to.RejectedVersions = from.RejectedVersions
+	to.Summary = from.Summary
+}
+
+// scanUsage - how the project's own code uses its dependencies; nil if it can't be scanned
+func scanUsage(gitRoot, gopath string, logger *utils.Logger) *apidiff.Usage {
+	exclude := make([]string, 0)
+	if gopath != "" {
+		exclude = append(exclude, gopath)
+	}
+	usage, err := apidiff.ScanUsage(gitRoot, exclude...)
+	if err != nil {
+		logger.LogInfo("not checking which code uses changed APIs: %v", err)
+		return nil
+	}
+	return usage
+}
+
+// analyzeEntries - analyze the entries on up to s.jobs workers. Entries that share a repository are analyzed in order by the same worker;
+// the results are written to the entries themselves, so the report keeps their order.
+func analyzeEntries(entries []*dep.Entry, gopath string, s *settings, usage *apidiff.Usage, logger *utils.Logger) {
+	if s.fetchGopath {
+		srcPath := path.Join(gopath, "src")
+		if !utils.DirExists(srcPath) {
+			err := os.Mkdir(srcPath, 0777)
+			if err != nil {
+				logger.PanicWithMessage("failed to create dir %s. error: %v", srcPath, err)
+			}
+		}
+	}
+	groups := groupEntries(entries, gopath, s, logger)
+	work := make(chan []*dep.Entry)
+	var wg sync.WaitGroup
+	for w := 0; w < s.jobs && w < len(groups); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range work {
+				fetched := newFetchedRepos()
+				for _, entry := range group {
+					logger.LogDebug("analysing entry %v", *entry)
+					if s.lsRemote && analyzeRemote(entry, gopath, s, fetched, usage, logger) {
+						if s.verify != "" && !entry.IsProblem && entry.RepoDir == "" {
+							fetchForVerification(entry, gopath, s, fetched, logger)
+						}
+						logger.LogDebug("** package %s - data: %v", entry.Path, *entry)
+						continue
+					}
+					vcs, err := openEntry(entry, gopath, s, fetched, logger)
+					if err != nil {
+						entry.IsProblem = true
+						entry.Summary = err.Error()
+						continue
+					}
+					analyzeEntry(entry, vcs, logger)
+					if !entry.IsUpdated && !entry.IsProblem {
+						collectChanges(entry, vcs, logger)
+						compareAPI(entry, vcs, usage, logger)
+					}
+					logger.LogDebug("** package %s - data: %v", entry.Path, *entry)
+				}
+				fetched.close()
+			}
+		}()
+	}
+	for _, group := range groups {
+		work <- group
+	}
+	close(work)
+	wg.Wait()
+}
+
+// groupEntries - the entries that a worker analyzes one after the other, because they share a repository:
+// the mirror they're fetched into and, with --fetchGopath, the GOPATH copy of the package, which is one working tree whatever its remote.
+func groupEntries(entries []*dep.Entry, gopath string, s *settings, logger *utils.Logger) [][]*dep.Entry {
+	groups := make([][]*dep.Entry, 0)
+	index := make(map[string]int)
+	for _, entry := range entries {
+		if entry.IsSkipped {
+			continue
+		}
+		keys := []string{mirrorOf(entry, gopath, s, logger)}
+		if s.fetchGopath {
+			keys = append(keys, path.Join(gopath, "src", entry.RepoPath()))
+		}
+		i := -1
+		for _, key := range keys {
+			j, ok := index[key]
+			switch {
+			case !ok:
+			case i == -1:
+				i = j
+			case i != j:
+				// the entry joins two groups, e.g. a fork mirrored with another package but sharing our GOPATH copy
+				groups[i] = append(groups[i], groups[j]...)
+				groups[j] = nil
+				for k, g := range index {
+					if g == j {
+						index[k] = i
+					}
+				}
+			}
+		}
+		if i == -1 {
+			i = len(groups)
+			groups = append(groups, nil)
+		}
+		for _, key := range keys {
+			index[key] = i
+		}
+		groups[i] = append(groups[i], entry)
+	}
+	nonEmpty := make([][]*dep.Entry, 0, len(groups))
+	for _, group := range groups {
+		if len(group) > 0 {
+			nonEmpty = append(nonEmpty, group)
+		}
+	}
+	return nonEmpty
+}
+
+// mirrorOf - the mirror an entry is fetched into; when its remote can't be resolved, its repository, the worker reports why
+func mirrorOf(entry *dep.Entry, gopath string, s *settings, logger *utils.Logger) string {
+	remote, err := remoteOf(entry, gopath, logger)
+	if err != nil {
+		return entry.RepoPath() + "|" + entry.GitRemote
+	}
+	return git.MirrorPath(s.cacheDir, remote)
+}
+
+// fetchForVerification - fetch an entry that ls-remote found up to date; the project is built against all its dependencies
+func fetchForVerification(entry *dep.Entry, gopath string, s *settings, fetched *fetchedRepos, logger *utils.Logger) {
+	if _, err := openEntry(entry, gopath, s, fetched, logger); err != nil {
+		logger.LogInfo("failed to fetch %s for the verification: %v", entry.Path, err)
+	}
+}
+
+// verifyEntries - build (and test, for the test mode) a copy of the project with the updates of a dependency file, one at a time or all together.
+// The project is built at its current versions first; when that fails too, the updates can't be judged and are left unverified.
+func verifyEntries(f *manifestFile, tipe, gopath string, s *settings, logger *utils.Logger) {
+	candidates := make([]*dep.Entry, 0)
+	for _, entry := range f.entries {
+		if dep.ShouldUpdate(entry) {
+			candidates = append(candidates, entry)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	unverified := func(reason string) {
+		for _, entry := range candidates {
+			entry.Verification = dep.VerifyUnverified
+			entry.VerifyOutput = reason
+		}
+	}
+	depPath, gitRoot := f.parser.DepPath(), f.parser.GitRoot()
+	_, module := f.parser.(*dep.GoModParser)
+	unvendor := make([]string, 0)
+	if !module {
+		for _, entry := range f.entries {
+			unvendor = append(unvendor, entry.RepoPath())
+		}
+	}
+	logger.LogInfo("verifying the updates of %s with go %s", depPath, s.verify)
+	ws, err := verify.New(gitRoot, projectImportPath(gitRoot, gopath, logger), gopath, module, unvendor, logger)
+	if err != nil {
+		unverified(err.Error())
+		return
+	}
+	defer ws.Close()
+	copyRoot, err := ws.Path(gitRoot)
+	if err != nil {
+		unverified(err.Error())
+		return
+	}
+	copyPath, err := ws.Path(depPath)
+	if err != nil {
+		unverified(err.Error())
+		return
+	}
+	parser, err := dep.NewParser(tipe, copyRoot, copyPath, logger)
+	if err != nil {
+		unverified(err.Error())
+		return
+	}
+	run := func(updates map[*dep.Entry]bool) (bool, string, error) {
+		if err := applyUpdates(ws, parser, f, updates); err != nil {
+			return false, "", err
+		}
+		return ws.Run(projectDir(depPath), s.verify)
+	}
+	record := func(entry *dep.Entry, passed bool, out string, err error) {
+		switch {
+		case err != nil:
+			entry.Verification = dep.VerifyUnverified
+			entry.VerifyOutput = err.Error()
+		case passed:
+			entry.Verification = dep.VerifyPassed
+		default:
+			entry.Verification = dep.VerifyFailed
+			entry.VerifyOutput = out
+		}
+	}
+
+	passed, out, err := run(nil)
+	if err != nil {
+		unverified(err.Error())
+		return
+	}
+	if !passed {
+		unverified("fails at the current versions as well:\n" + out)
+		return
+	}
+	if s.verifyAll {
+		bisectEntries(candidates, run, logger)
+		return
+	}
+	for _, entry := range candidates {
+		logger.LogInfo("verifying %s %s", entry.Path, entry.NewCommitVersion)
+		passed, out, err := run(map[*dep.Entry]bool{entry: true})
+		record(entry, passed, out, err)
+	}
+}
+
+// bisectEntries - verify the updates together; when they fail, bisect them until an update that breaks the project is isolated,
+// and repeat without it. The updates left pass together, so they're still applied.
+func bisectEntries(candidates []*dep.Entry, run func(map[*dep.Entry]bool) (bool, string, error), logger *utils.Logger) {
+	remaining := candidates
+	for len(remaining) > 0 {
+		passed, out, err := run(updateSet(remaining))
+		if err == nil && passed {
+			for _, entry := range remaining {
+				entry.Verification = dep.VerifyPassed
+			}
+			return
+		}
+		var culprit *dep.Entry
+		if err == nil {
+			logger.LogInfo("%d updates fail together, bisecting", len(remaining))
+			culprit, out, err = findBreaking(remaining, out, run, logger)
+		}
+		if err != nil {
+			for _, entry := range remaining {
+				entry.Verification = dep.VerifyUnverified
+				entry.VerifyOutput = err.Error()
+			}
+			return
+		}
+		logger.LogInfo("the update of %s to %s breaks the project", culprit.Path, culprit.NewCommitVersion)
+		culprit.Verification = dep.VerifyFailed
+		culprit.VerifyOutput = fmt.Sprintf("isolated by bisecting %d updates\n%s", len(remaining), out)
+		rest := make([]*dep.Entry, 0, len(remaining)-1)
+		for _, entry := range remaining {
+			if entry != culprit {
+				rest = append(rest, entry)
+			}
+		}
+		remaining = rest
+	}
+}
+
+// findBreaking - the update that breaks the project, out of updates that fail together (with out).
+// Each step applies the first half on top of the updates already known to pass; if that fails the culprit is in it, otherwise in the second half.
+func findBreaking(updates []*dep.Entry, out string, run func(map[*dep.Entry]bool) (bool, string, error), logger *utils.Logger) (*dep.Entry, string, error) {
+	base := make([]*dep.Entry, 0, len(updates))
+	for len(updates) > 1 {
+		half := updates[:len(updates)/2]
+		logger.LogDebug("trying %d updates on top of %d", len(half), len(base))
+		passed, halfOut, err := run(updateSet(append(base, half...)))
+		if err != nil {
+			return nil, "", err
+		}
+		if passed {
+			base = append(base, half...)
+			updates = updates[len(half):]
+		} else {
+			updates, out = half, halfOut
+		}
+	}
+	return updates[0], out, nil
+}
+
+// updateSet - the entries as a set, for applyUpdates
+func updateSet(entries []*dep.Entry) map[*dep.Entry]bool {
+	set := make(map[*dep.Entry]bool)
+	for _, entry := range entries {
+		set[entry] = true
+	}
+	return set
+}
+
+// applyUpdates - write the given updates, and only them, to the copy of the dependency file, and export the matching revisions into the workspace
+func applyUpdates(ws *verify.Workspace, parser dep.Parser, f *manifestFile, updates map[*dep.Entry]bool) error {
+	if err := ws.Reset(path.Dir(f.parser.DepPath())); err != nil {
+		return err
+	}
+	clones := make(map[*dep.Entry]*dep.Entry)
+	entries := make([]*dep.Entry, 0, len(f.entries))
+	for _, entry := range f.entries {
+		clone := *entry
+		clone.IsUpdated = !updates[entry]
+		clone.Verification = ""
+		clones[entry] = &clone
+		entries = append(entries, &clone)
+	}
+	entryMap := make(map[string]*dep.Entry)
+	for key, entry := range f.entryMap {
+		entryMap[key] = clones[entry]
+	}
+	dep.UpdateDependencyFile(parser, entries, f.content, f.contentMap, entryMap)
+	for _, entry := range f.entries {
+		if entry.IsSkipped || entry.IsProblem {
+			continue
+		}
+		rev := entry.CommitVersion
+		if entry.LockedRevision != "" {
+			rev = entry.LockedRevision
+		}
+		if updates[entry] {
+			rev = entry.NewRevision
+			if rev == "" {
+				rev = entry.NewCommitVersion
+			}
+		}
+		if entry.RepoDir == "" {
+			// not fetched, it's built from the --gopath copy if there's one
+			continue
+		}
+		if err := ws.Checkout(entry.RepoPath(), entry.RepoDir, rev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// projectDir - the directory the project of a dependency file is built in; godep and govendor keep theirs in a subdirectory
+func projectDir(depPath string) string {
+	dir := path.Dir(depPath)
+	if base := path.Base(dir); base == "Godeps" || base == "vendor" {
+		return path.Dir(dir)
+	}
+	return dir
+}
+
+// projectImportPath - the import path of the project: its place in the GOPATH, or else what its origin remote implies
+func projectImportPath(gitRoot, gopath string, logger *utils.Logger) string {
+	if src, err := filepath.Abs(path.Join(gopath, "src")); gopath != "" && err == nil {
+		if rel, err := filepath.Rel(src, gitRoot); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	if url, err := git.GetGitRemoteURL(gitRoot, logger); err == nil {
+		return git.ImportPathFromRemote(url)
 	}
+	return path.Base(gitRoot)
 }
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	git "github.com/tomeryakir/gdau/gitutils"
+	dep "github.com/tomeryakir/gdau/parsers"
+	"github.com/tomeryakir/gdau/semver"
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// hash - a fake commit hash made of one repeated hex digit
+func hash(digit string) string {
+	return strings.Repeat(digit, 40)
+}
+
+// fakeRepository - a history with releases on master and a develop branch:
+//
+//	1 v1.0.0 - 2 v1.0.1 - 3 - 4 v1.1.0 - 5 v2.0.0 (go.mod) - 6 v2.1.0-rc.1 - 7 master
+//	                           \
+//	                            8 - 9 develop
+func fakeRepository() *git.FakeVCS {
+	t := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int) time.Time { return t.AddDate(0, 0, days) }
+	v1 := map[string]string{"lib.go": "package lib\n\nfunc A() {}\n"}
+	v101 := map[string]string{"lib.go": "package lib\n\n// A - fixed\nfunc A() {}\n"}
+	v11 := map[string]string{"lib.go": "package lib\n\nfunc A() {}\n\nfunc B() {}\n", "CHANGELOG.md": "## v1.1.0\n- added B\n\n## v1.0.1\n- fixed A\n"}
+	v2 := map[string]string{"lib.go": "package lib\n\nfunc B() {}\n", "go.mod": "module example.com/lib/v2\n"}
+	return git.NewFakeVCS("https://github.com/acme/lib").
+		AddCommit(hash("1"), at(1), "one", v1).
+		AddCommit(hash("2"), at(2), "fix A", v101, hash("1")).
+		AddCommit(hash("3"), at(3), "work", v101, hash("2")).
+		AddCommit(hash("4"), at(4), "add B", v11, hash("3")).
+		AddCommit(hash("5"), at(5), "drop A", v2, hash("4")).
+		AddCommit(hash("6"), at(6), "rc", v2, hash("5")).
+		AddCommit(hash("7"), at(7), "after rc", v2, hash("6")).
+		AddCommit(hash("8"), at(8), "develop one", v1, hash("3")).
+		AddCommit(hash("9"), at(9), "develop two", v1, hash("8")).
+		SetBranch("master", hash("7")).
+		SetBranch("develop", hash("9")).
+		AddTag("v1.0.0", hash("1")).
+		AddTag("v1.0.1", hash("2")).
+		AddTag("v1.1.0", hash("4")).
+		AddTag("v2.0.0", hash("5")).
+		AddTag("v2.1.0-rc.1", hash("6"))
+}
+
+func newTestEntry(version string) *dep.Entry {
+	entry := dep.NewEntry("github.com/acme/lib", version, "")
+	entry.Policy = semver.PolicyMajor
+	entry.PreReleases = semver.PreReleaseStable
+	return entry
+}
+
+func TestAnalyzeEntryTagPins(t *testing.T) {
+	logger := utils.NewLogger(false)
+	tests := []struct {
+		name     string
+		version  string
+		policy   string
+		excluded []string
+		suffixes []string
+		major    int
+		want     string
+		latest   string
+		updated  bool
+		rejected string
+	}{
+		{name: "major", version: "v1.0.0", policy: semver.PolicyMajor, want: "v2.0.0", latest: "v2.0.0", rejected: "v2.1.0-rc.1 (pre-release)"},
+		{name: "minor", version: "v1.0.0", policy: semver.PolicyMinor, want: "v1.1.0", latest: "v2.0.0"},
+		{name: "patch", version: "v1.0.0", policy: semver.PolicyPatch, want: "v1.0.1", latest: "v2.0.0"},
+		{name: "excluded", version: "v1.0.0", policy: semver.PolicyMajor, excluded: []string{"v2.0.0"}, want: "v1.1.0", latest: "v1.1.0"},
+		{name: "up to date", version: "v2.0.0", policy: semver.PolicyMajor, want: "v2.0.0", latest: "v2.0.0", updated: true},
+		// a project whose releases are named like pre-releases
+		{name: "release suffix", version: "v1.1.0", policy: semver.PolicyMajor, suffixes: []string{"rc"}, want: "v2.1.0-rc.1", latest: "v2.1.0-rc.1"},
+		{name: "module path", version: "v1.0.0", policy: semver.PolicyMajor, major: 1, want: "v1.1.0", latest: "v2.0.0", rejected: "v2.0.0 (has a go.mod, major version 2 needs another module path)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry(tt.version)
+			entry.Policy = tt.policy
+			entry.ExcludedVersions = tt.excluded
+			entry.ReleaseSuffixes = tt.suffixes
+			if tt.major > 0 {
+				entry.ModuleMajor, entry.AllowsIncompatible = tt.major, true
+			}
+			analyzeEntry(entry, fakeRepository(), logger)
+			if entry.IsProblem {
+				t.Fatalf("unexpected problem: %s", entry.Summary)
+			}
+			if entry.NewCommitVersion != tt.want || entry.LatestVersion != tt.latest || entry.IsUpdated != tt.updated {
+				t.Errorf("got %s (latest %s, updated %v), want %s (latest %s, updated %v)", entry.NewCommitVersion, entry.LatestVersion, entry.IsUpdated, tt.want, tt.latest, tt.updated)
+			}
+			if tt.rejected != "" && !contains(entry.RejectedVersions, tt.rejected) {
+				t.Errorf("rejected %v, want %s among them", entry.RejectedVersions, tt.rejected)
+			}
+			if !tt.updated && (entry.Summary == "" || entry.DiffURL == "" || entry.NewCommitTime.IsZero()) {
+				t.Errorf("missing the summary, diff url or commit time of an update: %+v", entry)
+			}
+		})
+	}
+}
+
+func TestAnalyzeEntryBranchPins(t *testing.T) {
+	logger := utils.NewLogger(false)
+
+	// a version that names a branch follows it
+	entry := newTestEntry("develop")
+	analyzeEntry(entry, fakeRepository(), logger)
+	if entry.GitType != dep.Branch || entry.NewCommitVersion != "develop" || entry.NewRevision != hash("9") {
+		t.Errorf("got %v %s at %s, want a branch pin on develop at %s", entry.GitType, entry.NewCommitVersion, entry.NewRevision, hash("9"))
+	}
+
+	// a commit that follows a branch moves to its head
+	entry = newTestEntry(hash("3"))
+	entry.Branch = "develop"
+	analyzeEntry(entry, fakeRepository(), logger)
+	if entry.IsProblem || entry.IsUpdated || entry.NewCommitVersion != hash("9") || entry.CommitsBehind != 2 {
+		t.Errorf("got %s, %d commits behind (%s), want %s, 2 commits behind", entry.NewCommitVersion, entry.CommitsBehind, entry.Summary, hash("9"))
+	}
+
+	// a release that follows a branch (a branch rule) moves to its head
+	entry = newTestEntry("v1.0.1")
+	entry.GitType = dep.Tag
+	entry.Branch = "develop"
+	analyzeEntry(entry, fakeRepository(), logger)
+	if entry.IsProblem || entry.IsUpdated || entry.GitType != dep.Commit || entry.NewCommitVersion != hash("9") || entry.CommitsBehind != 3 {
+		t.Errorf("got %v %s, %d commits behind (%s), want a commit pin on %s, 3 commits behind", entry.GitType, entry.NewCommitVersion, entry.CommitsBehind, entry.Summary, hash("9"))
+	}
+
+	entry = newTestEntry(hash("3"))
+	entry.Branch = "missing"
+	analyzeEntry(entry, fakeRepository(), logger)
+	if !entry.IsProblem {
+		t.Errorf("a missing branch should be a problem, got %+v", entry)
+	}
+}
+
+func TestAnalyzeEntryCommitPins(t *testing.T) {
+	logger := utils.NewLogger(false)
+	tests := []struct {
+		name      string
+		commit    string
+		policy    string
+		trackHead bool
+		describe  string
+		want      string
+		gitType   dep.EntryType
+		summary   string
+	}{
+		{name: "tagged", commit: hash("2"), policy: semver.PolicyMajor, describe: "v1.0.1", want: "v2.0.0", gitType: dep.Tag, summary: "move from commit 222222222222 (≈ v1.0.1) to tag v2.0.0"},
+		{name: "after a tag", commit: hash("3"), policy: semver.PolicyMajor, describe: "v1.0.1+1", want: "v2.0.0", gitType: dep.Tag},
+		{name: "minor", commit: hash("3"), policy: semver.PolicyMinor, describe: "v1.0.1+1", want: "v1.1.0", gitType: dep.Tag},
+		{name: "patch", commit: hash("3"), policy: semver.PolicyPatch, describe: "v1.0.1+1", gitType: dep.Commit, summary: "no release is allowed by the patch policy"},
+		{name: "not released", commit: hash("8"), policy: semver.PolicyMajor, describe: "v1.0.1+2", gitType: dep.Commit, summary: "isn't in a release yet"},
+		{name: "track head", commit: hash("3"), policy: semver.PolicyMajor, trackHead: true, want: hash("7"), gitType: dep.Commit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry(tt.commit)
+			entry.Policy = tt.policy
+			entry.TrackHead = tt.trackHead
+			analyzeEntry(entry, fakeRepository(), logger)
+			if entry.IsProblem {
+				t.Fatalf("unexpected problem: %s", entry.Summary)
+			}
+			if entry.Describe != tt.describe || entry.NewCommitVersion != tt.want || entry.GitType != tt.gitType {
+				t.Errorf("got %s -> %s (%v), want %s -> %s (%v)", entry.Describe, entry.NewCommitVersion, entry.GitType, tt.describe, tt.want, tt.gitType)
+			}
+			if !strings.Contains(entry.Summary, tt.summary) {
+				t.Errorf("summary %q doesn't contain %q", entry.Summary, tt.summary)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCommitReleasesWithoutTags(t *testing.T) {
+	vcs := git.NewFakeVCS("https://github.com/acme/lib").
+		AddCommit(hash("1"), time.Now(), "one", nil).
+		SetBranch("master", hash("1"))
+	entry := newTestEntry(hash("1"))
+	if analyzeCommitReleases(entry, vcs, utils.NewLogger(false)) {
+		t.Errorf("a repository without release tags should be compared with HEAD, got %+v", entry)
+	}
+}
+
+func TestAnalyzeEntryUnknownTagCommit(t *testing.T) {
+	vcs := fakeRepository().AddTag("v3.0.0", hash("f"))
+	entry := newTestEntry("v3.0.0")
+	analyzeEntry(entry, vcs, utils.NewLogger(false))
+	if !entry.IsProblem {
+		t.Errorf("a tag of an unknown commit should be a problem, got %+v", entry)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAnalyzeEntryVersionRange(t *testing.T) {
+	logger := utils.NewLogger(false)
+	tests := []struct {
+		name    string
+		locked  string
+		current string
+		want    string
+	}{
+		{name: "locked at a release", locked: hash("2"), current: "v1.0.1", want: "v1.1.0"},
+		{name: "locked between releases", locked: hash("3"), current: hash("3"), want: "v1.1.0"},
+		{name: "locked at the newest release in the range", locked: hash("4"), current: "v1.1.0", want: "v1.1.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry("^1.0.0")
+			entry.GitType = dep.Tag
+			entry.Constraint, entry.ConstraintKind, entry.VersionRange = "^1.0.0", "version", "^1.0.0"
+			entry.LockedRevision = tt.locked
+			analyzeEntry(entry, fakeRepository(), logger)
+			if entry.IsProblem {
+				t.Fatalf("unexpected problem: %s", entry.Summary)
+			}
+			if entry.CommitVersion != tt.current || entry.NewCommitVersion != tt.want {
+				t.Errorf("got %s -> %s, want %s -> %s", entry.CommitVersion, entry.NewCommitVersion, tt.current, tt.want)
+			}
+			if !contains(entry.RejectedVersions, "v2.0.0 (outside the version range ^1.0.0)") {
+				t.Errorf("rejected %v, want v2.0.0 outside the range", entry.RejectedVersions)
+			}
+		})
+	}
+}
+
+func TestGroupEntries(t *testing.T) {
+	entries := []*dep.Entry{
+		dep.NewEntry("github.com/acme/lib", "v1.0.0", ""),
+		dep.NewEntry("github.com/acme/other", "v1.1.0", ""),
+		dep.NewEntry("github.com/acme/lib", "v1.0.0", "https://github.com/fork/lib"),
+		dep.NewEntry("github.com/acme/third", "v1.0.0", ""),
+		// fetched into the mirror of the fork
+		dep.NewEntry("example.com/lib", "v1.0.0", "git@github.com:fork/lib.git"),
+		dep.NewEntry("github.com/acme/third", "v1.1.0", "https://github.com/acme/third.git"),
+		// in the group of third by its mirror and in the group of other by its GOPATH copy
+		dep.NewEntry("github.com/acme/other", "v1.2.0", "https://github.com/acme/third"),
+	}
+	tests := []struct {
+		name        string
+		fetchGopath bool
+		want        []int
+	}{
+		{"by mirror", false, []int{1, 1, 2, 3}},
+		// the fork also shares the GOPATH copy of github.com/acme/lib
+		{"by mirror and GOPATH directory", true, []int{3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupEntries(entries, "/gopath", &settings{fetchGopath: tt.fetchGopath, cacheDir: "/cache"}, utils.NewLogger(false))
+			sizes := make([]int, 0, len(groups))
+			for _, group := range groups {
+				sizes = append(sizes, len(group))
+			}
+			if !reflect.DeepEqual(sizes, tt.want) {
+				t.Errorf("group sizes = %v, want %v", sizes, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeGoModExclude(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gdau-gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	depPath := filepath.Join(dir, "go.mod")
+	contents := "module example.com/app\n\nrequire github.com/acme/lib v1.0.0\n\nexclude github.com/acme/lib v1.1.0\n"
+	if err := ioutil.WriteFile(depPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logger := utils.NewLogger(false)
+	_, _, _, entryMap := dep.NewGoModParser(dir, depPath, logger).ReadFile(dir, depPath)
+	entry := entryMap["github.com/acme/lib"]
+	entry.Policy, entry.PreReleases = semver.PolicyMinor, semver.PreReleaseStable
+	analyzeEntry(entry, fakeRepository(), logger)
+	// v1.1.0 is excluded, v2.0.0 needs another module path
+	if entry.IsProblem || entry.NewCommitVersion != "v1.0.1" {
+		t.Errorf("got %s (%s), want v1.0.1", entry.NewCommitVersion, entry.Summary)
+	}
+	if !contains(entry.RejectedVersions, "v1.1.0 (excluded)") {
+		t.Errorf("rejected %v, want v1.1.0 excluded", entry.RejectedVersions)
+	}
+}
+
+func TestAnalyzeEntryTagSpelling(t *testing.T) {
+	logger := utils.NewLogger(false)
+	tests := []struct {
+		name    string
+		version string
+		vcs     *git.FakeVCS
+		current string
+	}{
+		{"version without the v of the tag", "1.0.1", fakeRepository(), "v1.0.1"},
+		{"version with a v the tag doesn't have", "v1.0.1", fakeRepository().AddTag("1.0.1", hash("2")), "v1.0.1"},
+		{"v the tag doesn't have", "v2.5.0", git.NewFakeVCS("https://github.com/acme/lib").
+			AddCommit(hash("1"), time.Now(), "one", nil).SetBranch("master", hash("1")).AddTag("2.5.0", hash("1")), "2.5.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry(tt.version)
+			entry.Constraint, entry.ConstraintKind = tt.version, "version"
+			analyzeEntry(entry, tt.vcs, logger)
+			if entry.IsProblem || entry.CommitVersion != tt.current {
+				t.Errorf("got %s (%s), want %s", entry.CommitVersion, entry.Summary, tt.current)
+			}
+		})
+	}
+}
+
+func TestBisectEntries(t *testing.T) {
+	tests := []struct {
+		name     string
+		updates  int
+		culprits []int
+	}{
+		{"no culprit", 5, nil},
+		{"one culprit", 5, []int{3}},
+		{"two culprits", 7, []int{0, 5}},
+		{"every update", 3, []int{0, 1, 2}},
+		{"a single update", 1, []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidates := make([]*dep.Entry, 0, tt.updates)
+			for i := 0; i < tt.updates; i++ {
+				candidates = append(candidates, dep.NewEntry(fmt.Sprintf("github.com/acme/lib%d", i), "v1.0.0", ""))
+			}
+			breaks := make(map[*dep.Entry]bool)
+			for _, i := range tt.culprits {
+				breaks[candidates[i]] = true
+			}
+			runs := 0
+			// a fake verifier: the build fails when any of the breaking updates is applied
+			run := func(updates map[*dep.Entry]bool) (bool, string, error) {
+				runs++
+				for entry := range updates {
+					if breaks[entry] {
+						return false, "build failed by " + entry.Path, nil
+					}
+				}
+				return true, "", nil
+			}
+			bisectEntries(candidates, run, utils.NewLogger(false))
+			for i, entry := range candidates {
+				want := dep.VerifyPassed
+				if breaks[entry] {
+					want = dep.VerifyFailed
+				}
+				if entry.Verification != want {
+					t.Errorf("update %d is %s, want %s", i, entry.Verification, want)
+				}
+				if breaks[entry] && !strings.Contains(entry.VerifyOutput, "build failed by "+entry.Path) {
+					t.Errorf("update %d has the output %q", i, entry.VerifyOutput)
+				}
+			}
+			if len(tt.culprits) == 0 && runs != 1 {
+				t.Errorf("passing updates were verified %d times, want once", runs)
+			}
+		})
+	}
+}
+
+func TestBisectEntriesError(t *testing.T) {
+	candidates := []*dep.Entry{dep.NewEntry("github.com/acme/a", "v1.0.0", ""), dep.NewEntry("github.com/acme/b", "v1.0.0", "")}
+	runs := 0
+	run := func(updates map[*dep.Entry]bool) (bool, string, error) {
+		runs++
+		if runs == 1 {
+			return false, "failed", nil
+		}
+		return false, "", fmt.Errorf("the workspace is gone")
+	}
+	bisectEntries(candidates, run, utils.NewLogger(false))
+	for _, entry := range candidates {
+		if entry.Verification != dep.VerifyUnverified || entry.VerifyOutput != "the workspace is gone" {
+			t.Errorf("%s is %s (%s), want unverified", entry.Path, entry.Verification, entry.VerifyOutput)
+		}
+	}
+}
+
+// gitRemote - a repository built with the git command line, for the analysis that reads remotes:
+//
+//	one v1.0.0 - two v1.1.0 - three (master)
+//
+// Returns its directory and the commits, each a day after the one before.
+func gitRemote(t *testing.T) (string, []string) {
+	dir, err := ioutil.TempDir("", "gdau-remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	when := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	run := func(args ...string) string {
+		t.Helper()
+		date := when.Format(time.RFC3339)
+		cmd := exec.Command("git", append([]string{"-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a",
+			"GIT_COMMITTER_EMAIL=a@example.com", "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q")
+	run("symbolic-ref", "HEAD", "refs/heads/master")
+	commits := make([]string, 0)
+	for i, subject := range []string{"one", "two", "three"} {
+		when = when.AddDate(0, 0, 1)
+		if err := ioutil.WriteFile(filepath.Join(dir, "lib.go"), []byte(fmt.Sprintf("package lib\n\n// %s\nfunc A() {}\n", subject)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "-A")
+		run("commit", "-q", "-m", subject)
+		commits = append(commits, run("rev-parse", "HEAD"))
+		if i < 2 {
+			run("tag", fmt.Sprintf("v1.%d.0", i))
+		}
+	}
+	return dir, commits
+}
+
+func TestAnalyzeRemoteFetches(t *testing.T) {
+	remote, commits := gitRemote(t)
+	cacheDir, err := ioutil.TempDir("", "gdau-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cacheDir)
+	day := func(n int) time.Time { return time.Date(2020, 1, 1+n, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name        string
+		updateFile  bool
+		recordsTime bool
+		branch      string
+		wantVersion string
+		wantFetched bool
+		wantTime    time.Time
+	}{
+		{"report only", false, false, "", "v1.1.0", false, time.Time{}},
+		// the lock and go.mod only take the tag
+		{"tag to tag", true, false, "", "v1.1.0", false, time.Time{}},
+		// vendor.json records the time of every revision
+		{"recorded time", true, true, "", "v1.1.0", true, day(2)},
+		{"recorded time without update", false, true, "", "v1.1.0", false, time.Time{}},
+		// a branch rule moves the release to a commit, which go.mod writes as a pseudo-version
+		{"tag to commit", true, false, "master", commits[2], true, day(3)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry("v1.0.0")
+			entry.GitRemote = remote
+			entry.RecordsCommitTime = tt.recordsTime
+			entry.Branch = tt.branch
+			s := &settings{lsRemote: true, updateFile: tt.updateFile, cacheDir: cacheDir}
+			fetched := newFetchedRepos()
+			defer fetched.close()
+			if !analyzeRemote(entry, "", s, fetched, nil, utils.NewLogger(false)) {
+				t.Fatalf("the entry was left to analyzeEntry")
+			}
+			if entry.IsProblem || entry.IsUpdated || entry.NewCommitVersion != tt.wantVersion {
+				t.Fatalf("problem %v, updated %v, version %s (%s), want an update to %s", entry.IsProblem, entry.IsUpdated, entry.NewCommitVersion, entry.Summary, tt.wantVersion)
+			}
+			if fetched := entry.RepoDir != ""; fetched != tt.wantFetched || !entry.NewCommitTime.Equal(tt.wantTime) {
+				t.Errorf("fetched %v at %v, want fetched %v at %v", fetched, entry.NewCommitTime, tt.wantFetched, tt.wantTime)
+			}
+		})
+	}
+}
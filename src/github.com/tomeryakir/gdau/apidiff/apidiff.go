@@ -0,0 +1,285 @@
+package apidiff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+)
+
+// API - the exported declarations of a repository: package directory -> identifier -> description.
+// Methods, struct fields and interface methods are keyed Type.Name.
+type API map[string]map[string]string
+
+// descriptions of types whose members are compared one by one
+const (
+	structType    = "type struct"
+	interfaceType = "type interface"
+)
+
+// Change - a difference in the exported API of a package
+type Change struct {
+	Package  string
+	Name     string
+	Breaking bool
+	Message  string
+}
+
+// String - the change as shown in the report
+func (c Change) String() string {
+	if c.Name == "" {
+		return fmt.Sprintf("%s: %s", c.Package, c.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s", c.Package, c.Name, c.Message)
+}
+
+// Extract - the exported API of Go source files, keyed by their path in the repository whose import path is importPath.
+// Files that don't parse are left out, and so are main packages.
+func Extract(importPath string, files map[string][]byte) API {
+	api := make(API)
+	fset := token.NewFileSet()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, name, files[name], parser.SkipObjectResolution)
+		if err != nil || f.Name.Name == "main" {
+			continue
+		}
+		dir := path.Join(importPath, path.Dir(name))
+		if api[dir] == nil {
+			api[dir] = make(map[string]string)
+		}
+		addDecls(api[dir], f)
+	}
+	return api
+}
+
+func addDecls(decls map[string]string, f *ast.File) {
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil {
+				addDecl(decls, d.Name.Name, "func"+signature(d.Type))
+				continue
+			}
+			recv := types.ExprString(d.Recv.List[0].Type)
+			base := strings.TrimPrefix(recv, "*")
+			if i := strings.Index(base, "["); i >= 0 {
+				base = base[:i]
+			}
+			if ast.IsExported(base) {
+				addDecl(decls, base+"."+d.Name.Name, fmt.Sprintf("method (%s) %s%s", recv, d.Name.Name, signature(d.Type)))
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						addType(decls, s)
+					}
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if !name.IsExported() {
+							continue
+						}
+						desc := d.Tok.String()
+						if s.Type != nil {
+							desc += " " + types.ExprString(s.Type)
+						}
+						addDecl(decls, name.Name, desc)
+					}
+				}
+			}
+		}
+	}
+}
+
+// declSeparator - joins the descriptions of a name that files for different platforms declare differently
+const declSeparator = " or "
+
+// addDecl - record the description of a declaration. Files for other platforms (build tags, _linux.go) may declare the same name,
+// possibly differently; all the descriptions are kept, so neither variant overwrites the other.
+func addDecl(decls map[string]string, name, desc string) {
+	old, ok := decls[name]
+	if !ok {
+		decls[name] = desc
+		return
+	}
+	variants := strings.Split(old, declSeparator)
+	for _, v := range variants {
+		if v == desc {
+			return
+		}
+	}
+	variants = append(variants, desc)
+	sort.Strings(variants)
+	decls[name] = strings.Join(variants, declSeparator)
+}
+
+func addType(decls map[string]string, s *ast.TypeSpec) {
+	name := s.Name.Name
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		addDecl(decls, name, structType)
+		for _, field := range t.Fields.List {
+			typ := types.ExprString(field.Type)
+			if len(field.Names) == 0 {
+				// embedded, named after its type
+				embedded := strings.TrimPrefix(typ, "*")
+				embedded = embedded[strings.LastIndex(embedded, ".")+1:]
+				if ast.IsExported(embedded) {
+					addDecl(decls, name+"."+embedded, "embedded "+typ)
+				}
+				continue
+			}
+			for _, fieldName := range field.Names {
+				if fieldName.IsExported() {
+					addDecl(decls, name+"."+fieldName.Name, "field "+typ)
+				}
+			}
+		}
+	case *ast.InterfaceType:
+		addDecl(decls, name, interfaceType)
+		for _, method := range t.Methods.List {
+			if len(method.Names) == 0 {
+				addDecl(decls, name+"."+types.ExprString(method.Type), "embedded interface")
+				continue
+			}
+			if ft, ok := method.Type.(*ast.FuncType); ok {
+				addDecl(decls, name+"."+method.Names[0].Name, "method "+method.Names[0].Name+signature(ft))
+			}
+		}
+	default:
+		op := " "
+		if s.Assign.IsValid() {
+			op = " = "
+		}
+		addDecl(decls, name, "type"+op+types.ExprString(s.Type))
+	}
+}
+
+// signature - parameter and result types of a function, without the parameter names
+func signature(ft *ast.FuncType) string {
+	sig := "(" + strings.Join(fieldTypes(ft.Params), ", ") + ")"
+	results := fieldTypes(ft.Results)
+	switch {
+	case len(results) == 1:
+		sig += " " + results[0]
+	case len(results) > 1:
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+func fieldTypes(fl *ast.FieldList) []string {
+	list := make([]string, 0)
+	if fl == nil {
+		return list
+	}
+	for _, field := range fl.List {
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			list = append(list, types.ExprString(field.Type))
+		}
+	}
+	return list
+}
+
+// Diff - the API changes from old to new; removed and changed declarations break callers,
+// and so does a method added to an interface (implementations no longer satisfy it)
+func Diff(old, new API) []Change {
+	changes := make([]Change, 0)
+	for _, pkg := range sortedKeys(old, new) {
+		oldDecls, newDecls := old[pkg], new[pkg]
+		if newDecls == nil {
+			changes = append(changes, Change{pkg, "", true, "package removed"})
+			continue
+		}
+		if oldDecls == nil {
+			changes = append(changes, Change{pkg, "", false, "package added"})
+			continue
+		}
+		for _, name := range sortedNames(oldDecls, newDecls) {
+			before, inOld := oldDecls[name]
+			after, inNew := newDecls[name]
+			parent := ""
+			if i := strings.Index(name, "."); i >= 0 {
+				parent = name[:i]
+			}
+			switch {
+			case inOld && !inNew:
+				if parent != "" && oldDecls[parent] != "" && newDecls[parent] == "" {
+					// reported with its type
+					continue
+				}
+				changes = append(changes, Change{pkg, name, true, "removed"})
+			case !inOld && inNew:
+				if parent != "" && oldDecls[parent] == interfaceType && newDecls[parent] == interfaceType {
+					changes = append(changes, Change{pkg, name, true, "added to interface: " + after})
+					continue
+				}
+				if parent != "" && oldDecls[parent] == "" {
+					continue
+				}
+				changes = append(changes, Change{pkg, name, false, "added"})
+			case before != after:
+				changes = append(changes, Change{pkg, name, true, fmt.Sprintf("changed from %s to %s", before, after)})
+			}
+		}
+	}
+	return changes
+}
+
+// IsBreaking - whether any of the changes breaks callers
+func IsBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(a, b API) []string {
+	seen := make(map[string]bool)
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedSet(seen)
+}
+
+func sortedNames(a, b map[string]string) []string {
+	seen := make(map[string]bool)
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedSet(seen)
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
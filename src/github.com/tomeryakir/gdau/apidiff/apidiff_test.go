@@ -0,0 +1,132 @@
+package apidiff
+
+import (
+	"reflect"
+	"testing"
+)
+
+const libV1 = `package lib
+
+// Open - open a thing
+func Open(name string) error { return nil }
+
+func Close() {}
+
+func helper() {}
+
+type Reader interface {
+	Read(p []byte) (int, error)
+}
+
+type Config struct {
+	Name    string
+	Timeout int
+	secret  string
+}
+
+func (c *Config) Validate() error { return nil }
+
+const Version = "1.0"
+`
+
+const libV2 = `package lib
+
+// Open - open a thing, with options
+func Open(name string, options ...string) error { return nil }
+
+func helper(n int) {}
+
+type Reader interface {
+	Read(p []byte) (int, error)
+	Close() error
+}
+
+type Config struct {
+	Name    string
+	Timeout int64
+	Retries int
+}
+
+func (c *Config) Validate() error { return nil }
+
+const Version = "2.0"
+
+func New() *Config { return nil }
+`
+
+func TestDiff(t *testing.T) {
+	old := Extract("example.com/lib", map[string][]byte{
+		"lib.go":           []byte(libV1),
+		"gone/gone.go":     []byte("package gone\n\nfunc Gone() {}\n"),
+		"cmd/tool/main.go": []byte("package main\n\nfunc Main() {}\n"),
+		"broken/b.go":      []byte("package broken\n\nfunc {\n"),
+	})
+	new := Extract("example.com/lib", map[string][]byte{
+		"lib.go":         []byte(libV2),
+		"added/added.go": []byte("package added\n\nfunc Added() {}\n"),
+	})
+	if _, ok := old["example.com/lib/cmd/tool"]; ok {
+		t.Errorf("a main package is part of the API")
+	}
+	if _, ok := old["example.com/lib/broken"]; ok {
+		t.Errorf("a file that doesn't parse is part of the API")
+	}
+	want := []string{
+		"example.com/lib.Close: removed",
+		"example.com/lib.Config.Retries: added",
+		"example.com/lib.Config.Timeout: changed from field int to field int64",
+		"example.com/lib.New: added",
+		"example.com/lib.Open: changed from func(string) error to func(string, ...string) error",
+		"example.com/lib.Reader.Close: added to interface: method Close() error",
+		"example.com/lib/added: package added",
+		"example.com/lib/gone: package removed",
+	}
+	changes := Diff(old, new)
+	got := make([]string, 0, len(changes))
+	for _, c := range changes {
+		got = append(got, c.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+	breaking := map[string]bool{"Close": true, "Config.Timeout": true, "Open": true, "Reader.Close": true}
+	for _, c := range changes {
+		if c.Name != "" && c.Breaking != breaking[c.Name] {
+			t.Errorf("%s is breaking: %v", c, c.Breaking)
+		}
+	}
+	if !IsBreaking(changes) || IsBreaking(Diff(new, new)) {
+		t.Errorf("IsBreaking is wrong")
+	}
+}
+
+func TestDiffRemovedType(t *testing.T) {
+	old := Extract("example.com/lib", map[string][]byte{"lib.go": []byte(libV1)})
+	new := Extract("example.com/lib", map[string][]byte{"lib.go": []byte("package lib\n\nfunc Open(name string) error { return nil }\n\nfunc Close() {}\n\ntype Reader interface {\n\tRead(p []byte) (int, error)\n}\n\nconst Version = \"1.0\"\n")})
+	// the fields and methods of a removed type are reported with it
+	want := []Change{{"example.com/lib", "Config", true, "removed"}}
+	if got := Diff(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExtractBuildTagFiles(t *testing.T) {
+	unix := "// +build !windows\n\npackage lib\n\nfunc Open(name string) (int, error) { return 0, nil }\n\nconst Sep = '/'\n"
+	windows := "// +build windows\n\npackage lib\n\nfunc Open(name string) (uintptr, error) { return 0, nil }\n\nconst Sep = '\\\\'\n"
+	old := Extract("example.com/lib", map[string][]byte{"open_unix.go": []byte(unix), "open_windows.go": []byte(windows)})
+	if got, want := old["example.com/lib"]["Open"], "func(string) (int, error) or func(string) (uintptr, error)"; got != want {
+		t.Errorf("Open = %q, want both variants %q", got, want)
+	}
+
+	// the files are read in any order, neither variant wins
+	same := Extract("example.com/lib", map[string][]byte{"a_windows.go": []byte(windows), "b_unix.go": []byte(unix)})
+	if changes := Diff(old, same); len(changes) != 0 {
+		t.Errorf("the same platform files changed: %v", changes)
+	}
+
+	changed := "// +build windows\n\npackage lib\n\nfunc Open(name string, flags int) (uintptr, error) { return 0, nil }\n\nconst Sep = '\\\\'\n"
+	changes := Diff(old, Extract("example.com/lib", map[string][]byte{"open_unix.go": []byte(unix), "open_windows.go": []byte(changed)}))
+	if len(changes) != 1 || changes[0].Name != "Open" || !changes[0].Breaking {
+		t.Errorf("a signature change on one platform = %v, want Open changed", changes)
+	}
+}
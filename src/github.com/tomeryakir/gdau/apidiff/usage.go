@@ -0,0 +1,168 @@
+package apidiff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// reference - a selector in our source: pkg.Name for identifiers of an imported package, x.Name otherwise
+type reference struct {
+	name     string
+	position string
+}
+
+// sourceFile - the imports of one of our files and the selectors it uses
+type sourceFile struct {
+	imports  map[string]string // import path -> position of the import
+	pkgRefs  map[string][]reference
+	selector map[string][]reference
+}
+
+// Usage - how our source uses other packages
+type Usage struct {
+	files []*sourceFile
+}
+
+// ScanUsage - parse the Go files under root; vendor, testdata, hidden and the excluded directories are left out
+func ScanUsage(root string, exclude ...string) (*Usage, error) {
+	skip := make(map[string]bool)
+	for _, dir := range exclude {
+		if abs, err := filepath.Abs(dir); err == nil {
+			skip[abs] = true
+		}
+	}
+	u := &Usage{}
+	fset := token.NewFileSet()
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if abs, err := filepath.Abs(p); err == nil && skip[abs] {
+				return filepath.SkipDir
+			}
+			if p != root && (name == "vendor" || name == "testdata" || name == "node_modules" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		f, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			// not our problem here, the build will tell
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			rel = p
+		}
+		u.files = append(u.files, scanFile(fset, f, rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s. error: %v", root, err)
+	}
+	return u, nil
+}
+
+func scanFile(fset *token.FileSet, f *ast.File, rel string) *sourceFile {
+	sf := &sourceFile{imports: make(map[string]string), pkgRefs: make(map[string][]reference), selector: make(map[string][]reference)}
+	position := func(pos token.Pos) string {
+		return fmt.Sprintf("%s:%d", rel, fset.Position(pos).Line)
+	}
+	names := make(map[string]string) // local name -> import path
+	for _, imp := range f.Imports {
+		importPath, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		sf.imports[importPath] = position(imp.Pos())
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		} else if strings.HasPrefix(name, "v") && isNumber(name[1:]) && strings.Contains(importPath, "/") {
+			// a /vN module path, the package is named after the element before it
+			trimmed := importPath[:strings.LastIndex(importPath, "/")]
+			name = trimmed[strings.LastIndex(trimmed, "/")+1:]
+		} else if i := strings.Index(name, ".v"); i > 0 {
+			// gopkg.in/yaml.v2
+			name = name[:i]
+		}
+		names[name] = importPath
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ref := reference{sel.Sel.Name, position(sel.Sel.Pos())}
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			if importPath, ok := names[ident.Name]; ok {
+				sf.pkgRefs[importPath] = append(sf.pkgRefs[importPath], ref)
+				return true
+			}
+		}
+		sf.selector[ref.name] = append(sf.selector[ref.name], ref)
+		return true
+	})
+	return sf
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// Affected - the places in our source that use what the breaking changes removed or changed.
+// Members (Type.Name) are matched by name in the files that import the package, so those are only possible uses.
+func (u *Usage) Affected(changes []Change) []string {
+	affected := make([]string, 0)
+	if u == nil {
+		return affected
+	}
+	seen := make(map[string]bool)
+	add := func(s string) {
+		if !seen[s] {
+			seen[s] = true
+			affected = append(affected, s)
+		}
+	}
+	for _, change := range changes {
+		if !change.Breaking {
+			continue
+		}
+		for _, sf := range u.files {
+			importPos, ok := sf.imports[change.Package]
+			if !ok {
+				continue
+			}
+			switch {
+			case change.Name == "":
+				add(fmt.Sprintf("%s: imports %s (%s)", importPos, change.Package, change.Message))
+			case strings.Contains(change.Name, "."):
+				member := change.Name[strings.Index(change.Name, ".")+1:]
+				for _, ref := range sf.selector[member] {
+					add(fmt.Sprintf("%s: possibly uses %s", ref.position, change))
+				}
+			default:
+				for _, ref := range sf.pkgRefs[change.Package] {
+					if ref.name == change.Name {
+						add(fmt.Sprintf("%s: uses %s", ref.position, change))
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(affected)
+	return affected
+}
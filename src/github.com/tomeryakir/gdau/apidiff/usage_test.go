@@ -0,0 +1,82 @@
+package apidiff
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeProject - a project of files (name -> contents) in a temporary directory that is removed when the test ends
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "gdau-usage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestAffected(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"example.com/lib"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func main() {
+	c := lib.New()
+	c.Validate()
+	lib.Open("x")
+	yaml.Marshal(c)
+}
+`,
+		"v2/use.go":        "package v2\n\nimport \"example.com/other/v2\"\n\nvar _ = other.Gone\n",
+		"gone/use.go":      "package gone\n\nimport \"example.com/lib/gone\"\n\nvar _ = gone.Gone\n",
+		"vendor/x/x.go":    "package x\n\nimport \"example.com/lib\"\n\nvar _ = lib.Open\n",
+		"testdata/t.go":    "package t\n\nimport \"example.com/lib\"\n\nvar _ = lib.Open\n",
+		"excluded/e.go":    "package e\n\nimport \"example.com/lib\"\n\nvar _ = lib.Open\n",
+		"broken/broken.go": "package broken\n\nfunc {\n",
+	})
+	usage, err := ScanUsage(root, filepath.Join(root, "excluded"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := []Change{
+		{"example.com/lib", "Open", true, "changed from func(string) error to func(string, int) error"},
+		{"example.com/lib", "Close", true, "removed"},
+		{"example.com/lib", "Config.Validate", true, "removed"},
+		{"example.com/lib", "Added", false, "added"},
+		{"example.com/lib/gone", "", true, "package removed"},
+		{"example.com/other/v2", "Gone", true, "removed"},
+		{"gopkg.in/yaml.v2", "Marshal", true, "removed"},
+	}
+	want := []string{
+		"gone/use.go:3: imports example.com/lib/gone (package removed)",
+		"main.go:10: possibly uses example.com/lib.Config.Validate: removed",
+		"main.go:11: uses example.com/lib.Open: changed from func(string) error to func(string, int) error",
+		"main.go:12: uses gopkg.in/yaml.v2.Marshal: removed",
+		"v2/use.go:5: uses example.com/other/v2.Gone: removed",
+	}
+	if got := usage.Affected(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%v\nwant\n%v", got, want)
+	}
+
+	var none *Usage
+	if got := none.Affected(changes); len(got) != 0 {
+		t.Errorf("a project that wasn't scanned has affected code: %v", got)
+	}
+}
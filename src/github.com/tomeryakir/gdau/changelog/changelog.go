@@ -0,0 +1,102 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tomeryakir/gdau/semver"
+)
+
+// FileNames - the prefixes of the file names a changelog is looked up by, case insensitive
+var FileNames = []string{"changelog", "changes", "news"}
+
+// MaxLines - the longest excerpt Between returns
+const MaxLines = 200
+
+var (
+	headingVersion = regexp.MustCompile(`v?[0-9]+\.[0-9]+(\.[0-9]+)?(-[0-9A-Za-z.]+)?`)
+	versionLine    = regexp.MustCompile(`^\[?v?[0-9]+\.[0-9]+`)
+)
+
+// IsChangelog - whether a file name looks like a changelog (CHANGELOG.md, CHANGES, NEWS.txt, ...)
+func IsChangelog(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range FileNames {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// section - a changelog heading and the lines under it
+type section struct {
+	version *semver.Version
+	level   int
+	lines   []string
+}
+
+// headingLevel - the level of a heading, or 0 if the line isn't one: markdown headings (## v1.2.0) by their #s,
+// underlined headings (1.2.0 followed by === or ---) as 1 and 2, and lines that start with a version as 1
+func headingLevel(lines []string, i int) int {
+	line := lines[i]
+	if strings.HasPrefix(line, "#") {
+		return len(line) - len(strings.TrimLeft(line, "#"))
+	}
+	if i+1 < len(lines) {
+		next := strings.TrimSpace(lines[i+1])
+		if len(next) >= 3 && strings.TrimSpace(line) != "" {
+			if strings.Trim(next, "=") == "" {
+				return 1
+			}
+			if strings.Trim(next, "-") == "" {
+				return 2
+			}
+		}
+	}
+	if versionLine.MatchString(line) {
+		return 1
+	}
+	return 0
+}
+
+// parse - split a changelog into sections. A heading starts a section when it carries a version, or when it's no deeper than the version heading
+// of the current section; deeper ones (### Added under ## [1.1.0]) stay in the section of their version.
+func parse(contents string) []*section {
+	lines := strings.Split(strings.Replace(contents, "\r\n", "\n", -1), "\n")
+	sections := make([]*section, 0)
+	var current *section
+	for i, line := range lines {
+		if level := headingLevel(lines, i); level > 0 {
+			v, err := semver.Parse(headingVersion.FindString(line))
+			if err == nil || current == nil || current.version == nil || level <= current.level {
+				current = &section{level: level}
+				if err == nil {
+					current.version = v
+				}
+				sections = append(sections, current)
+			}
+		}
+		if current != nil {
+			current.lines = append(current.lines, line)
+		}
+	}
+	return sections
+}
+
+// Between - the changelog sections of the versions after from, up to and including to.
+// to may be nil (e.g. when updating to the latest commit), then every section after from is returned.
+func Between(contents string, from, to *semver.Version) string {
+	excerpt := make([]string, 0)
+	for _, s := range parse(contents) {
+		if s.version == nil || s.version.Compare(from) <= 0 || to != nil && s.version.Compare(to) > 0 {
+			continue
+		}
+		excerpt = append(excerpt, strings.TrimRight(strings.Join(s.lines, "\n"), "\n "))
+	}
+	text := strings.Join(excerpt, "\n\n")
+	if lines := strings.Split(text, "\n"); len(lines) > MaxLines {
+		text = strings.Join(lines[:MaxLines], "\n") + "\n..."
+	}
+	return text
+}
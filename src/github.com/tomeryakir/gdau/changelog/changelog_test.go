@@ -0,0 +1,125 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/semver"
+)
+
+const keepAChangelog = `# Changelog
+All notable changes to this project will be documented in this file.
+
+## [Unreleased]
+### Added
+- upcoming C
+
+## [1.2.0] - 2020-02-01
+### Added
+- new C
+### Fixed
+- fixed B
+
+## [1.1.0] - 2020-01-01
+### Added
+- new B
+
+## [1.0.0] - 2019-12-01
+### Added
+- A
+`
+
+func mustParse(t *testing.T, v string) *semver.Version {
+	t.Helper()
+	version, err := semver.Parse(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		from     string
+		to       string
+		want     string
+	}{
+		{
+			name:     "sub-headings stay in their version",
+			contents: "## [1.1.0] - 2020-01-01\n### Added\n- new B",
+			from:     "v1.0.0",
+			to:       "v1.1.0",
+			want:     "## [1.1.0] - 2020-01-01\n### Added\n- new B",
+		},
+		{
+			name:     "keep a changelog",
+			contents: keepAChangelog,
+			from:     "v1.0.0",
+			to:       "v1.2.0",
+			want:     "## [1.2.0] - 2020-02-01\n### Added\n- new C\n### Fixed\n- fixed B\n\n## [1.1.0] - 2020-01-01\n### Added\n- new B",
+		},
+		{
+			name:     "up to a commit",
+			contents: keepAChangelog,
+			from:     "v1.1.0",
+			want:     "## [1.2.0] - 2020-02-01\n### Added\n- new C\n### Fixed\n- fixed B",
+		},
+		{
+			name:     "a heading as deep as the version ends it",
+			contents: "## v1.1.0\n- new B\n## Contributors\n- someone\n## v1.0.0\n- A",
+			from:     "v1.0.0",
+			to:       "v1.1.0",
+			want:     "## v1.1.0\n- new B",
+		},
+		{
+			name:     "underlined headings",
+			contents: "1.1.0\n=====\n\nAdded\n-----\n- new B\n\n1.0.0\n=====\n- A\n",
+			from:     "1.0.0",
+			to:       "1.1.0",
+			want:     "1.1.0\n=====\n\nAdded\n-----\n- new B",
+		},
+		{
+			name:     "version lines",
+			contents: "v1.1.0 (2020-01-01)\n  * new B\nv1.0.0 (2019-12-01)\n  * A\n",
+			from:     "1.0.0",
+			to:       "1.1.0",
+			want:     "v1.1.0 (2020-01-01)\n  * new B",
+		},
+		{
+			name:     "nothing new",
+			contents: keepAChangelog,
+			from:     "v1.2.0",
+			to:       "v1.2.0",
+			want:     "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var to *semver.Version
+			if tt.to != "" {
+				to = mustParse(t, tt.to)
+			}
+			if got := Between(tt.contents, mustParse(t, tt.from), to); got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBetweenMaxLines(t *testing.T) {
+	contents := "## v1.1.0\n" + strings.Repeat("- a change\n", MaxLines*2)
+	got := Between(contents, mustParse(t, "1.0.0"), nil)
+	if lines := strings.Split(got, "\n"); len(lines) != MaxLines+1 || lines[MaxLines] != "..." {
+		t.Errorf("got %d lines, want %d and ...", len(lines), MaxLines)
+	}
+}
+
+func TestIsChangelog(t *testing.T) {
+	for name, want := range map[string]bool{"CHANGELOG.md": true, "Changes": true, "NEWS.txt": true, "changelog": true, "README.md": false, "main.go": false} {
+		if got := IsChangelog(name); got != want {
+			t.Errorf("IsChangelog(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/tomeryakir/gdau/semver"
+	"github.com/tomeryakir/gdau/yaml"
+)
+
+// FileName - the name of the project configuration file
+const FileName = ".gdau.yaml"
+
+// Config - project settings, read from .gdau.yaml:
+//
+//	policy: minor
+//	prereleases: stable
+//	releaseSuffixes:
+//	  - devops
+//	ignore:
+//	  - github.com/acme/fork-*
+//	dependencies:
+//	  - path: github.com/foo/bar
+//	    ceiling: v1
+//	    tagPrefix: release-
+//	    trackHead: true
+//	    branch: develop
+//	    remote: git@github.com:acme/bar.git
+//	    policy: patch
+//
+// releaseSuffixes are pre-release identifiers that mark releases, e.g. devops for a project that releases v1.0.0-devops.
+type Config struct {
+	Path            string
+	Policy          string
+	PreReleases     string
+	ReleaseSuffixes []string
+	Ignore          []string
+	Dependencies    []*Rule
+}
+
+// Rule - settings for the dependencies whose import path matches Path
+type Rule struct {
+	Path        string
+	Ignore      bool
+	Pin         string
+	Ceiling     string
+	TagPrefix   string
+	TrackHead   bool
+	Branch      string
+	Remote      string
+	Policy      string
+	PreReleases string
+}
+
+// String - the rule as shown in the report
+func (r *Rule) String() string {
+	settings := make([]string, 0)
+	if r.Ignore {
+		settings = append(settings, "ignored")
+	}
+	if r.TrackHead {
+		settings = append(settings, "tracks HEAD")
+	}
+	for _, s := range [][2]string{{"pin", r.Pin}, {"ceiling", r.Ceiling}, {"tag prefix", r.TagPrefix}, {"branch", r.Branch}, {"remote", r.Remote}, {"policy", r.Policy}, {"prereleases", r.PreReleases}} {
+		if s[1] != "" {
+			settings = append(settings, fmt.Sprintf("%s %s", s[0], s[1]))
+		}
+	}
+	return fmt.Sprintf("%s: %s", r.Path, strings.Join(settings, ", "))
+}
+
+// Match - whether an import path matches a pattern: the exact path, a glob (github.com/acme/*), or a prefix (github.com/acme/...)
+func Match(pattern, importPath string) bool {
+	if strings.HasSuffix(pattern, "/...") {
+		prefix := strings.TrimSuffix(pattern, "/...")
+		return importPath == prefix || strings.HasPrefix(importPath, prefix+"/")
+	}
+	matched, err := path.Match(pattern, importPath)
+	return importPath == pattern || err == nil && matched
+}
+
+// RuleFor - the first rule matching the import path; an ignore pattern counts as a rule that only ignores
+func (c *Config) RuleFor(importPath string) *Rule {
+	if c == nil {
+		return nil
+	}
+	for _, pattern := range c.Ignore {
+		if Match(pattern, importPath) {
+			return &Rule{Path: pattern, Ignore: true}
+		}
+	}
+	for _, rule := range c.Dependencies {
+		if Match(rule.Path, importPath) {
+			return rule
+		}
+	}
+	return nil
+}
+
+// Find - the configuration file next to the dependency file, or else at the git root; empty if there's none
+func Find(depPath, gitRoot string) string {
+	for _, dir := range []string{path.Dir(depPath), gitRoot} {
+		configPath := path.Join(dir, FileName)
+		if _, err := os.Stat(configPath); err == nil {
+			return configPath
+		}
+	}
+	return ""
+}
+
+// Load - read and validate a configuration file
+func Load(configPath string) (*Config, error) {
+	contents, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s. error: %v", configPath, err)
+	}
+	doc, err := yaml.Parse(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s. error: %v", configPath, err)
+	}
+	if err := checkKeys(doc.Root, configKeys); err != nil {
+		return nil, fmt.Errorf("%s: %v", configPath, err)
+	}
+	c := &Config{
+		Path:            configPath,
+		Policy:          doc.Root.String("policy"),
+		PreReleases:     doc.Root.String("prereleases"),
+		ReleaseSuffixes: doc.Root.Strings("releaseSuffixes"),
+		Ignore:          doc.Root.Strings("ignore"),
+	}
+	for _, suffix := range c.ReleaseSuffixes {
+		if suffix == "" || strings.ContainsAny(suffix, ".+ ") {
+			return nil, fmt.Errorf("%s: line %d: invalid release suffix %q, it's one pre-release identifier such as devops", configPath, doc.Root.KeyLine("releaseSuffixes"), suffix)
+		}
+	}
+	for _, item := range doc.Root.List("dependencies") {
+		if item.Kind != yaml.Mapping {
+			return nil, fmt.Errorf("%s: line %d: a dependency rule needs a path", configPath, item.Line)
+		}
+		if err := checkKeys(item, ruleKeys); err != nil {
+			return nil, fmt.Errorf("%s: %v", configPath, err)
+		}
+		ignore, err := boolean(item, "ignore")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", configPath, err)
+		}
+		trackHead, err := boolean(item, "trackHead")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", configPath, err)
+		}
+		rule := &Rule{
+			Path:        item.String("path"),
+			Ignore:      ignore,
+			Pin:         item.String("pin"),
+			Ceiling:     item.String("ceiling"),
+			TagPrefix:   item.String("tagPrefix"),
+			TrackHead:   trackHead,
+			Branch:      item.String("branch"),
+			Remote:      item.String("remote"),
+			Policy:      item.String("policy"),
+			PreReleases: item.String("prereleases"),
+		}
+		if rule.Path == "" {
+			return nil, fmt.Errorf("%s: line %d: a dependency rule needs a path", configPath, item.Line)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("%s: line %d: %v", configPath, item.Line, err)
+		}
+		c.Dependencies = append(c.Dependencies, rule)
+	}
+	if err := (&Rule{Policy: c.Policy, PreReleases: c.PreReleases}).validate(); err != nil {
+		return nil, fmt.Errorf("%s: %v", configPath, err)
+	}
+	return c, nil
+}
+
+// the keys a configuration file and its dependency rules can have
+var (
+	configKeys = []string{"policy", "prereleases", "releaseSuffixes", "ignore", "dependencies"}
+	ruleKeys   = []string{"path", "ignore", "pin", "ceiling", "tagPrefix", "trackHead", "branch", "remote", "policy", "prereleases"}
+)
+
+// checkKeys - fail on a key that isn't one of keys, which would otherwise be ignored silently
+func checkKeys(n *yaml.Node, keys []string) error {
+	for _, key := range n.Keys {
+		known := false
+		for _, k := range keys {
+			known = known || k == key
+		}
+		if !known {
+			return fmt.Errorf("line %d: unknown key %s (can be %s)", n.KeyLine(key), key, strings.Join(keys, ", "))
+		}
+	}
+	return nil
+}
+
+// boolean - a true or false setting; false when it isn't set
+func boolean(n *yaml.Node, key string) (bool, error) {
+	v := n.Get(key)
+	if v == nil {
+		return false, nil
+	}
+	if v.Kind == yaml.Scalar && (v.Value == "true" || v.Value == "false") {
+		return v.Value == "true", nil
+	}
+	return false, fmt.Errorf("line %d: %s must be true or false", n.KeyLine(key), key)
+}
+
+func (r *Rule) validate() error {
+	if r.Policy != "" && !semver.IsPolicy(r.Policy) {
+		return fmt.Errorf("unsupported policy %s (can be %s)", r.Policy, strings.Join(semver.Policies(), ", "))
+	}
+	if r.PreReleases != "" && !semver.IsPreReleaseMode(r.PreReleases) {
+		return fmt.Errorf("unsupported prereleases %s (can be %s)", r.PreReleases, strings.Join(semver.PreReleaseModes(), ", "))
+	}
+	if r.Ceiling != "" {
+		if _, err := semver.Parse(r.Ceiling); err != nil {
+			return fmt.Errorf("invalid ceiling %s", r.Ceiling)
+		}
+	}
+	if r.Branch != "" && r.TrackHead {
+		return fmt.Errorf("a rule can't both track HEAD and branch %s", r.Branch)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "gdau-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	configPath := filepath.Join(dir, FileName)
+	if err := ioutil.WriteFile(configPath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return configPath
+}
+
+func TestLoad(t *testing.T) {
+	configPath := writeConfig(t, `policy: minor
+releaseSuffixes:
+  - devops
+  - hotfix
+ignore:
+  - github.com/acme/fork-*
+dependencies:
+  - path: github.com/foo/bar
+    branch: develop
+  - path: github.com/foo/...
+    trackHead: true
+    ignore: false
+`)
+	c, err := Load(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.ReleaseSuffixes, []string{"devops", "hotfix"}) {
+		t.Errorf("release suffixes = %v", c.ReleaseSuffixes)
+	}
+	if rule := c.RuleFor("github.com/foo/bar"); rule == nil || rule.Branch != "develop" || rule.TrackHead {
+		t.Errorf("RuleFor(github.com/foo/bar) = %v, want the develop branch rule", rule)
+	}
+	if rule := c.RuleFor("github.com/foo/baz"); rule == nil || !rule.TrackHead || rule.Ignore {
+		t.Errorf("RuleFor(github.com/foo/baz) = %v, want the trackHead rule", rule)
+	}
+	if rule := c.RuleFor("github.com/acme/fork-x"); rule == nil || !rule.Ignore {
+		t.Errorf("RuleFor(github.com/acme/fork-x) = %v, want an ignore rule", rule)
+	}
+	if s := c.RuleFor("github.com/foo/bar").String(); s != "github.com/foo/bar: branch develop" {
+		t.Errorf("String = %s", s)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"unknown rule key", "dependencies:\n  - path: github.com/foo/bar\n    brnch: develop\n", "line 3: unknown key brnch"},
+		{"unknown key", "policy: minor\ntrackhead: true\n", "line 2: unknown key trackhead"},
+		{"not a boolean", "dependencies:\n  - path: github.com/foo/bar\n    trackHead: yes\n", "line 3: trackHead must be true or false"},
+		{"ignore not a boolean", "dependencies:\n  - path: github.com/foo/bar\n    ignore: 1\n", "line 3: ignore must be true or false"},
+		{"no path", "dependencies:\n  - policy: minor\n", "line 2: a dependency rule needs a path"},
+		{"branch and trackHead", "dependencies:\n  - path: github.com/foo/bar\n    branch: develop\n    trackHead: true\n", "line 2: a rule can't both track HEAD and branch develop"},
+		{"policy", "policy: newest\n", "unsupported policy newest"},
+		{"release suffix", "policy: minor\nreleaseSuffixes:\n  - devops.1\n", `line 2: invalid release suffix "devops.1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := writeConfig(t, tt.contents)
+			_, err := Load(configPath)
+			if err == nil || !strings.HasPrefix(err.Error(), configPath+": ") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load = %v, want an error of %s with %q", err, configPath, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package gitutils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FakeVCS - an in-memory repository for tests: commits, branches and tags are added to it directly
+type FakeVCS struct {
+	Remote string
+	// DefaultBranch - the branch LatestCommit reads
+	DefaultBranch string
+	// FetchErr - what Fetch returns
+	FetchErr error
+	// Fetches - how many times Fetch was called
+	Fetches  int
+	commits  map[string]*fakeCommit
+	branches map[string]string
+	tags     []Tag
+}
+
+type fakeCommit struct {
+	Commit
+	parents []string
+	files   map[string]string
+}
+
+// NewFakeVCS - an empty repository fetched from remote, whose default branch is master
+func NewFakeVCS(remote string) *FakeVCS {
+	return &FakeVCS{Remote: remote, DefaultBranch: "master", commits: make(map[string]*fakeCommit), branches: make(map[string]string)}
+}
+
+// AddCommit - add a commit with the files of its tree (path -> contents) on top of its parents
+func (f *FakeVCS) AddCommit(hash string, t time.Time, subject string, files map[string]string, parents ...string) *FakeVCS {
+	f.commits[hash] = &fakeCommit{Commit{hash, t, subject}, parents, files}
+	return f
+}
+
+// SetBranch - point a branch at a commit
+func (f *FakeVCS) SetBranch(branch, commit string) *FakeVCS {
+	f.branches[branch] = commit
+	return f
+}
+
+// AddTag - tag a commit; tags are listed in the order they're added, and dated by their commit
+func (f *FakeVCS) AddTag(name, commit string) *FakeVCS {
+	date := time.Time{}
+	if c, ok := f.commits[commit]; ok {
+		date = c.Time
+	}
+	f.tags = append(f.tags, Tag{name, commit, date})
+	return f
+}
+
+// RemoteURL - the remote the fake was created with
+func (f *FakeVCS) RemoteURL() (string, error) {
+	return f.Remote, nil
+}
+
+// Fetch - count the fetch and return FetchErr
+func (f *FakeVCS) Fetch() error {
+	f.Fetches++
+	return f.FetchErr
+}
+
+// Tags - the tags in the order they were added
+func (f *FakeVCS) Tags() ([]Tag, error) {
+	return append([]Tag(nil), f.tags...), nil
+}
+
+func (f *FakeVCS) resolve(ref string) (*fakeCommit, error) {
+	name := ref
+	if name == "HEAD" {
+		name = "refs/heads/" + f.DefaultBranch
+	}
+	if hash, ok := f.branches[strings.TrimPrefix(name, "refs/heads/")]; ok {
+		return f.commit(ref, hash)
+	}
+	for _, tag := range f.tags {
+		if tag.Name == strings.TrimPrefix(name, "refs/tags/") {
+			return f.commit(ref, tag.Commit)
+		}
+	}
+	if strings.HasPrefix(name, "refs/") {
+		return nil, fmt.Errorf("%s wasn't found", ref)
+	}
+	var found *fakeCommit
+	for hash, c := range f.commits {
+		if len(name) >= 4 && strings.HasPrefix(hash, name) {
+			if found != nil {
+				return nil, fmt.Errorf("%s is ambiguous", ref)
+			}
+			found = c
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%s wasn't found", ref)
+	}
+	return found, nil
+}
+
+// commit - the commit a branch or tag points at, which may not have been added
+func (f *FakeVCS) commit(ref, hash string) (*fakeCommit, error) {
+	c, ok := f.commits[hash]
+	if !ok {
+		return nil, fmt.Errorf("%s points at unknown commit %s", ref, hash)
+	}
+	return c, nil
+}
+
+// ResolveRef - the commit of a branch, tag or (abbreviated) commit hash
+func (f *FakeVCS) ResolveRef(ref string) (*Commit, error) {
+	c, err := f.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	commit := c.Commit
+	return &commit, nil
+}
+
+// LatestCommit - the head of the default branch
+func (f *FakeVCS) LatestCommit() (*Commit, error) {
+	return f.ResolveRef("HEAD")
+}
+
+func (f *FakeVCS) node(hash string) ([]string, time.Time, error) {
+	c, ok := f.commits[hash]
+	if !ok {
+		return nil, time.Time{}, fmt.Errorf("commit %s wasn't found", hash)
+	}
+	return c.parents, c.Time, nil
+}
+
+// Log - the commits of to that aren't in from, newest first
+func (f *FakeVCS) Log(from, to string) ([]*Commit, error) {
+	toCommit, err := f.resolve(to)
+	if err != nil {
+		return nil, err
+	}
+	fromHash := ""
+	if from != "" {
+		fromCommit, err := f.resolve(from)
+		if err != nil {
+			return nil, err
+		}
+		fromHash = fromCommit.Hash
+	}
+	hashes, err := walkLog(f, fromHash, toCommit.Hash)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]*Commit, 0, len(hashes))
+	for _, hash := range hashes {
+		commit := f.commits[hash].Commit
+		commits = append(commits, &commit)
+	}
+	return commits, nil
+}
+
+// IsAncestor - whether ancestor is in the history of commit
+func (f *FakeVCS) IsAncestor(ancestor, commit string) (bool, error) {
+	a, err := f.resolve(ancestor)
+	if err != nil {
+		return false, err
+	}
+	c, err := f.resolve(commit)
+	if err != nil {
+		return false, err
+	}
+	return reachable(f, a.Hash, c.Hash)
+}
+
+// DiffStats - the git diff --shortstat summary between the files of two refs
+func (f *FakeVCS) DiffStats(from, to string) (string, error) {
+	a, err := f.resolve(from)
+	if err != nil {
+		return "", err
+	}
+	b, err := f.resolve(to)
+	if err != nil {
+		return "", err
+	}
+	// the contents are their own ids
+	return shortStat(a.files, b.files, func(id string) ([]byte, error) { return []byte(id), nil })
+}
+
+// Files - the files of a ref whose paths match
+func (f *FakeVCS) Files(ref string, match func(name string) bool) (map[string][]byte, error) {
+	c, err := f.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for name, contents := range c.files {
+		if match(name) {
+			files[name] = []byte(contents)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,61 @@
+package gitutils
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFakeVCSUnknownCommit(t *testing.T) {
+	f := NewFakeVCS("https://github.com/acme/lib").
+		AddCommit("aaaa1111", time.Now(), "one", nil).
+		SetBranch("master", "aaaa1111").
+		SetBranch("gone", "bbbb2222").
+		AddTag("v1.0.0", "cccc3333")
+	for _, ref := range []string{"gone", "refs/heads/gone", "v1.0.0", "refs/tags/v1.0.0", "dddd", "refs/heads/missing"} {
+		if c, err := f.ResolveRef(ref); err == nil {
+			t.Errorf("ResolveRef(%s) = %v, want an error", ref, c)
+		}
+	}
+	if c, err := f.ResolveRef("master"); err != nil || c.Hash != "aaaa1111" {
+		t.Errorf("ResolveRef(master) = %v, %v", c, err)
+	}
+}
+
+func TestFakeVCSHelpers(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFakeVCS("https://github.com/acme/lib").
+		AddCommit("aaaa1111", start, "one", map[string]string{"a.go": "package a\nfunc A() {}\n", "a_test.go": "package a\n"}).
+		AddCommit("bbbb2222", start.Add(time.Hour), "two", map[string]string{"a.go": "package a\nfunc B() {}\n", "go.mod": "module a\n"}, "aaaa1111").
+		AddCommit("cccc3333", start.Add(2*time.Hour), "side", map[string]string{"a.go": "package a\n"}, "aaaa1111").
+		AddCommit("dddd4444", start.Add(3*time.Hour), "merge", map[string]string{"a.go": "package a\nfunc B() {}\n"}, "bbbb2222", "cccc3333").
+		SetBranch("master", "dddd4444").
+		AddTag("v1.0.0", "aaaa1111").
+		AddTag("v1.1.0", "bbbb2222")
+
+	if described, err := DescribeCommit(f, "dddd4444"); err != nil || described != "v1.1.0+2" {
+		t.Errorf("DescribeCommit = %s, %v, want v1.1.0+2", described, err)
+	}
+	containing, err := GetTagsContaining(f, "aaaa1111")
+	if want := map[string]bool{"v1.0.0": true, "v1.1.0": true}; err != nil || !reflect.DeepEqual(containing, want) {
+		t.Errorf("GetTagsContaining = %v, %v, want %v", containing, err, want)
+	}
+	log, more, err := GetCommitLog(f, "v1.0.0", "master", 2)
+	if want := []string{"dddd444 merge", "cccc333 side"}; err != nil || more != 1 || !reflect.DeepEqual(log, want) {
+		t.Errorf("GetCommitLog = %v, %d, %v, want %v, 1", log, more, err, want)
+	}
+	if stat, err := f.DiffStats("v1.0.0", "v1.1.0"); err != nil || stat != " 3 files changed, 2 insertions(+), 2 deletions(-)" {
+		t.Errorf("DiffStats = %q, %v", stat, err)
+	}
+	if files, err := GetGoFiles(f, "v1.0.0"); err != nil || len(files) != 1 {
+		t.Errorf("GetGoFiles = %v, %v, want a.go only", files, err)
+	}
+	for ref, want := range map[string]bool{"v1.0.0": false, "v1.1.0": true} {
+		if has, err := HasFile(f, ref, "go.mod"); err != nil || has != want {
+			t.Errorf("HasFile(%s, go.mod) = %v, %v, want %v", ref, has, err, want)
+		}
+	}
+	if !IsTag(f, "v1.1.0") || IsTag(f, "master") || !IsBranch(f, "master") || IsBranch(f, "v1.1.0") {
+		t.Errorf("IsTag/IsBranch mixed up tags and branches")
+	}
+}
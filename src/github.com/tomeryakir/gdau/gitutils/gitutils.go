@@ -5,106 +5,83 @@ import (
 	"os"
 	"os/exec"
 	"path"
-	"strconv"
+	"sort"
 	"strings"
+	"sync"
 
+	"github.com/tomeryakir/gdau/semver"
 	"github.com/tomeryakir/gdau/utils"
 )
 
-// GetCommitDiffSummary - getting diff summary between two commits
-func GetCommitDiffSummary(gitpath string, oldcommit, newcommit string, logger *utils.Logger) (string, error) {
-	// git diff --shortstat oldcommit newcommit
-	logger.LogDebug("getting diff summary for %s", gitpath)
-	cmd := exec.Command("git", "-C", gitpath, "diff", "--shortstat", oldcommit, newcommit)
-	logger.LogDebug("running command %v", *cmd)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("failed to get git diff for %s. err: %v. out: %v", gitpath, err, string(out))
+func isPublicGoFile(name string) bool {
+	if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+		return false
 	}
-	lines := strings.Split(string(out), "\n")
-	if len(lines) == 0 {
-		return "", fmt.Errorf("Failed to get git diff output for %s", gitpath)
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		if dir == "vendor" || dir == "testdata" || dir == "internal" || strings.HasPrefix(dir, "_") || strings.HasPrefix(dir, ".") && dir != "." {
+			return false
+		}
 	}
-	return lines[0], nil
+	return true
 }
 
-// GetLatestGitCommit - getting latest git commit
-func GetLatestGitCommit(gitpath string, logger *utils.Logger) (string, string, error) {
-	// git --no-pager log --pretty=format:"%H,%cd,%cr"
-	cmd := exec.Command("git", "--no-pager", "-C", gitpath, "log", "--pretty=format:\"%H;%cd;%cr\"", "-n", "1")
-	logger.LogDebug("running command %v", *cmd)
-	out, err := cmd.Output()
-	if err != nil {
-		logger.PanicWithMessage("failed to get git log for %s. err: %v", gitpath, err)
-	}
-	lines := strings.Split(string(out), "\n")
-	if len(lines) == 0 {
-		return "", "", fmt.Errorf("Failed to get git log output for %s", gitpath)
-	}
-	tokens := strings.Split(lines[0], ";")
-	return utils.ClearQuotes(tokens[0]), fmt.Sprintf("%s (%s)", tokens[1], utils.ClearQuotes(tokens[2])), nil
+// RejectedTag - a tag that wasn't considered as the latest release, and why
+type RejectedTag struct {
+	Tag    string
+	Reason string
 }
 
-// GetLatestGitCommitByTag - getting latest git commit by tag
-func GetLatestGitCommitByTag(gitpath string, logger *utils.Logger) (string, string, string, error) {
-	ignorableTags := []string{"rc", "night", "unstable", "beta", "alpha", "dev"}
-	acceptedTagPrefixes := []string{"v", "r"}
-	cmd := exec.Command("git", "--no-pager", "-C", gitpath, "tag", "--format=\"%(creatordate:iso);%(creatordate:relative);%(refname:strip=2)\"", "--sort=creatordate")
-	logger.LogDebug("running command %v", *cmd)
-	out, err := cmd.Output()
-	if err != nil {
-		logger.PanicWithMessage(fmt.Sprintf("failed to get git log tag %s. err: %v", gitpath, err)) // TODO - replace panic
+// ReleaseTag - a tag that is a semantic version, either a release or a pre-release
+type ReleaseTag struct {
+	Name         string
+	Version      *semver.Version
+	Date         string
+	RelativeDate string
+}
+
+// DateSummary - the tag date as shown in the report; empty for tags read from a remote, which have no date
+func (t *ReleaseTag) DateSummary() string {
+	if t.Date == "" {
+		return ""
 	}
-	lines := strings.Split(string(out), "\n")
-	if len(lines) == 0 {
-		return "", "", "", fmt.Errorf("Failed to get git tag output for %s", gitpath)
-	}
-	var latestTag string
-	var latestTagDate string
-	var latestTagRelDate string
-	for _, line := range lines {
-		tokens := strings.Split(line, ";")
-		if len(tokens) < 2 {
+	return fmt.Sprintf("%s (%s)", t.Date, t.RelativeDate)
+}
+
+// listedTag - a tag name with its date, as git lists it
+type listedTag struct {
+	name         string
+	date         string
+	relativeDate string
+}
+
+// releaseTags - the semantic version tags out of the listed tags, lowest to highest; source names the repository in messages
+func releaseTags(source string, listed []listedTag, excludedTags []string, tagPrefix string, logger *utils.Logger) ([]*ReleaseTag, []RejectedTag, error) {
+	tags := make([]*ReleaseTag, 0)
+	rejected := make([]RejectedTag, 0)
+	for _, l := range listed {
+		tag := l.name
+		if stringEquals(excludedTags, tag) {
+			logger.LogDebug("tag %s is excluded for %s", tag, source)
+			rejected = append(rejected, RejectedTag{tag, "excluded"})
 			continue
 		}
-		if tokens[2] == "" || len(tokens[2]) < 2 {
+		if !strings.HasPrefix(tag, tagPrefix) {
+			rejected = append(rejected, RejectedTag{tag, fmt.Sprintf("doesn't start with %s", tagPrefix)})
 			continue
 		}
-		tagPrefix := string(tokens[2][0])
-		tagPostPrefix := string(tokens[2][1])
-		if !stringContains(ignorableTags, tokens[2]) && ((stringEquals(acceptedTagPrefixes, tagPrefix) && stringIsNumber(tagPostPrefix)) || stringIsNumber(tagPrefix)) {
-			latestTagDate = tokens[0]
-			latestTagRelDate = tokens[1]
-			latestTag = tokens[2]
+		version, err := semver.Parse(strings.TrimPrefix(tag, tagPrefix))
+		if err != nil {
+			rejected = append(rejected, RejectedTag{tag, "not a semantic version"})
+			continue
 		}
+		tags = append(tags, &ReleaseTag{tag, version, l.date, l.relativeDate})
 	}
-	commit, err := GetCommitByTag(gitpath, latestTag, logger)
-	if err != nil {
-		return "", "", "", fmt.Errorf("failed to get commit for tag %s of package %s", latestTag, gitpath)
+	if len(tags) == 0 {
+		return nil, rejected, fmt.Errorf("no semantic version tags found for package %s", source)
 	}
-	return utils.ClearQuotes(commit), utils.ClearQuotes(latestTag), fmt.Sprintf("%s (%s)", utils.ClearQuotes(latestTagDate), utils.ClearQuotes(latestTagRelDate)), nil
-}
-
-// GetCommitByTag - getting commit for tag
-func GetCommitByTag(gitpath, tag string, logger *utils.Logger) (string, error) {
-	cmd := exec.Command("git", "--no-pager", "-C", gitpath, "log", "--pretty=format:\"%H\"", "-1", utils.ClearQuotes(tag))
-	logger.LogDebug("running command %v", *cmd)
-	out, err := cmd.Output()
-	if err != nil {
-		logger.PanicWithMessage("failed to get git log tag %s. err: %v", gitpath, err)
-	}
-	lines := strings.Split(string(out), "\n")
-	if len(lines) == 0 {
-		return "", fmt.Errorf("Failed to get git tag output for %s", gitpath)
-	}
-	return utils.ClearQuotes(lines[0]), nil
-}
-
-func stringIsNumber(v string) bool {
-	if _, err := strconv.Atoi(v); err == nil {
-		return true
-	}
-	return false
+	// tags come oldest first, so of two equal versions (v1.2, 1.2.0) the newer tag ends up last
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Version.Compare(tags[j].Version) < 0 })
+	return tags, rejected, nil
 }
 
 func stringEquals(s []string, v string) bool {
@@ -116,15 +93,6 @@ func stringEquals(s []string, v string) bool {
 	return false
 }
 
-func stringContains(s []string, v string) bool {
-	for _, sv := range s {
-		if strings.Contains(v, sv) {
-			return true
-		}
-	}
-	return false
-}
-
 // GetGitRemoteURL - get remote origin url
 func GetGitRemoteURL(gitpath string, logger *utils.Logger) (string, error) {
 	var err error
@@ -153,7 +121,12 @@ func GetGitRemoteURL(gitpath string, logger *utils.Logger) (string, error) {
 
 // GetGitRoot - get git root
 func GetGitRoot(godepsPath string, logger *utils.Logger) string {
-	cmd := exec.Command("git", "-C", path.Dir(godepsPath), "rev-parse", "--show-toplevel")
+	return GetGitRootOfDir(path.Dir(godepsPath), logger)
+}
+
+// GetGitRootOfDir - get git root of a directory
+func GetGitRootOfDir(dir string, logger *utils.Logger) string {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel")
 	logger.LogDebug("running command %v", *cmd)
 	out, err := cmd.Output()
 	if err != nil {
@@ -162,15 +135,23 @@ func GetGitRoot(godepsPath string, logger *utils.Logger) string {
 	return strings.Trim(string(out), "\n")
 }
 
-// Goget - get go package
+// gogetLock - go get fetches the dependencies of a package into the shared GOPATH as well, so only one runs at a time
+var gogetLock sync.Mutex
+
+// Goget - get go package into the GOPATH; packages with an ssh remote are cloned from it directly
 func Goget(gopath, gogetpath, packagePath, gitremote string, logger *utils.Logger) error {
 	logger.LogDebug("getting package %s", gogetpath)
+	if IsSSHRemote(gitremote) {
+		return clone(gitremote, packagePath, logger)
+	}
 	cmd := exec.Command("go", "get", gogetpath)
 	cmd.Dir = path.Join(gopath, "src")
 	cmd.Env = os.Environ()
 	cmd.Env = append(cmd.Env, fmt.Sprintf("GOPATH=%s", gopath))
 	logger.LogDebug("running command %v", *cmd)
+	gogetLock.Lock()
 	out, err := cmd.CombinedOutput()
+	gogetLock.Unlock()
 	if err != nil {
 		if !strings.Contains(string(out), "no Go files in") {
 			return fmt.Errorf("failed to run go get for package %s.\nout: %v\nerr: %v", gogetpath, string(out), err)
@@ -179,7 +160,28 @@ func Goget(gopath, gogetpath, packagePath, gitremote string, logger *utils.Logge
 	return AddRemote(gogetpath, gitremote, packagePath, logger)
 }
 
-// AddRemote - add remote repo to path
+// clone - git clone a remote into packagePath
+func clone(gitremote, packagePath string, logger *utils.Logger) error {
+	cmd := exec.Command("git", "clone", gitremote, packagePath)
+	cmd.Env = sshEnv()
+	logger.LogDebug("running command %v", *cmd)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to run git clone for %s.\nout: %v\nerr: %v", gitremote, string(out), err)
+	}
+	return nil
+}
+
+// sshEnv - environment for git commands that may go over ssh; fail instead of prompting for passwords or host keys
+func sshEnv() []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	return env
+}
+
+// AddRemote - add the remote to a GOPATH copy as downstream, and fetch it
 func AddRemote(gogetpath, gitremote, packagePath string, logger *utils.Logger) error {
 	if gitremote != "" {
 		logger.LogDebug("adding remote %s to %s", gitremote, packagePath)
@@ -193,7 +195,8 @@ func AddRemote(gogetpath, gitremote, packagePath string, logger *utils.Logger) e
 			}
 		}
 		// git fetch downstream
-		cmd = exec.Command("git", "-C", packagePath, "fetch", "downstream")
+		cmd = exec.Command("git", "-C", packagePath, "fetch", "--tags", "downstream")
+		cmd.Env = sshEnv()
 		logger.LogDebug("running command %v", *cmd)
 		out, err = cmd.CombinedOutput()
 		if err != nil {
@@ -203,7 +206,7 @@ func AddRemote(gogetpath, gitremote, packagePath string, logger *utils.Logger) e
 	return nil
 }
 
-// Gitpull - git pull
+// Gitpull - check out the default branch of a GOPATH copy and pull it
 func Gitpull(packagePath string, logger *utils.Logger) {
 	// get default branch
 	cmd := exec.Command("git", "-C", packagePath, "ls-remote", "--symref")
@@ -231,6 +234,7 @@ func Gitpull(packagePath string, logger *utils.Logger) {
 		logger.LogInfo("failed to run git checkout for package %s.\nout: %v\nerr: %v", packagePath, string(out), err)
 	}
 	cmd = exec.Command("git", "-C", packagePath, "pull")
+	cmd.Env = sshEnv()
 	logger.LogDebug("running command %v", *cmd)
 	out, err = cmd.CombinedOutput()
 	if err != nil {
@@ -0,0 +1,225 @@
+package gitutils
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// GitVCS - a repository read and fetched with the git command line
+type GitVCS struct {
+	dir    string
+	remote string
+	logger *utils.Logger
+}
+
+// NewGitVCS - the repository (a mirror) at dir, fetched from remote
+func NewGitVCS(dir, remote string, logger *utils.Logger) *GitVCS {
+	return &GitVCS{dir, remote, logger}
+}
+
+// RemoteURL - the remote the repository was created with, or its configured origin
+func (g *GitVCS) RemoteURL() (string, error) {
+	if g.remote != "" {
+		return g.remote, nil
+	}
+	return GetGitRemoteURL(g.dir, g.logger)
+}
+
+// Fetch - create the mirror, or fetch what's new into it
+func (g *GitVCS) Fetch() error {
+	remote, err := g.RemoteURL()
+	if err != nil {
+		return err
+	}
+	return FetchMirror(g.dir, remote, g.logger)
+}
+
+func (g *GitVCS) output(args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"--no-pager", "-C", g.dir}, args...)...)
+	g.logger.LogDebug("running command %v", *cmd)
+	return cmd.Output()
+}
+
+// Tags - the tags, oldest first; annotated tags are peeled to their commits
+func (g *GitVCS) Tags() ([]Tag, error) {
+	out, err := g.output("for-each-ref", "--sort=creatordate", "--format=%(creatordate:iso-strict);%(objectname);%(*objectname);%(*objecttype);%(refname:strip=2)", "refs/tags")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the tags of %s. err: %v", g.dir, err)
+	}
+	tags := make([]Tag, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		tokens := strings.SplitN(line, ";", 5)
+		if len(tokens) < 5 || tokens[4] == "" {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, tokens[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the date of tag %s of %s. err: %v", tokens[4], g.dir, err)
+		}
+		commit := tokens[1]
+		if tokens[2] != "" {
+			commit = tokens[2]
+		}
+		if tokens[3] == "tag" {
+			// a tag of a tag is only peeled once by for-each-ref
+			peeled, err := g.output("rev-parse", "--verify", "--quiet", "refs/tags/"+tokens[4]+"^{commit}")
+			if err != nil {
+				// e.g. a tag of a tree
+				continue
+			}
+			commit = strings.TrimSpace(string(peeled))
+		}
+		tags = append(tags, Tag{tokens[4], commit, date})
+	}
+	return tags, nil
+}
+
+// commits - the commits git log lists for args, newest first
+func (g *GitVCS) commits(args ...string) ([]*Commit, error) {
+	out, err := g.output(append([]string{"log", "--format=%H;%cI;%s"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]*Commit, 0)
+	for _, line := range strings.Split(string(out), "\n") {
+		tokens := strings.SplitN(line, ";", 3)
+		if len(tokens) < 3 {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, tokens[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the date of commit %s of %s. err: %v", tokens[0], g.dir, err)
+		}
+		commits = append(commits, &Commit{tokens[0], t, tokens[2]})
+	}
+	return commits, nil
+}
+
+// ResolveRef - the commit a ref points at
+func (g *GitVCS) ResolveRef(ref string) (*Commit, error) {
+	commits, err := g.commits("-n", "1", utils.ClearQuotes(ref), "--")
+	if err != nil || len(commits) == 0 {
+		return nil, fmt.Errorf("failed to get the commit of %s for %s. err: %v", ref, g.dir, err)
+	}
+	return commits[0], nil
+}
+
+// LatestCommit - the head of the default branch, which the mirror's HEAD follows
+func (g *GitVCS) LatestCommit() (*Commit, error) {
+	return g.ResolveRef("HEAD")
+}
+
+// DiffStats - git diff --shortstat between two refs
+func (g *GitVCS) DiffStats(from, to string) (string, error) {
+	cmd := exec.Command("git", "-C", g.dir, "diff", "--shortstat", from, to)
+	g.logger.LogDebug("running command %v", *cmd)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git diff for %s. err: %v. out: %v", g.dir, err, string(out))
+	}
+	return strings.Split(string(out), "\n")[0], nil
+}
+
+// Log - the commits of to that aren't in from, newest first
+func (g *GitVCS) Log(from, to string) ([]*Commit, error) {
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+	commits, err := g.commits(rev, "--")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git log between %s and %s for %s. err: %v", from, to, g.dir, err)
+	}
+	return commits, nil
+}
+
+// IsAncestor - whether ancestor is in the history of commit
+func (g *GitVCS) IsAncestor(ancestor, commit string) (bool, error) {
+	cmd := exec.Command("git", "-C", g.dir, "merge-base", "--is-ancestor", ancestor, commit)
+	g.logger.LogDebug("running command %v", *cmd)
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check whether %s is in the history of %s for %s. err: %v", ancestor, commit, g.dir, err)
+	}
+	return true, nil
+}
+
+// Files - the contents of the files of a ref whose paths match, read from git archive
+func (g *GitVCS) Files(ref string, match func(name string) bool) (map[string][]byte, error) {
+	out, err := g.output("archive", "--format=tar", ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive %s for %s. err: %v", ref, g.dir, err)
+	}
+	files := make(map[string][]byte)
+	reader := tar.NewReader(bytes.NewReader(out))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the archive of %s for %s. err: %v", ref, g.dir, err)
+		}
+		if header.Typeflag != tar.TypeReg || !match(header.Name) {
+			continue
+		}
+		contents, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of %s for %s. err: %v", header.Name, ref, g.dir, err)
+		}
+		files[header.Name] = contents
+	}
+	return files, nil
+}
+
+// Describe - git describe, as DescribeCommit formats it
+func (g *GitVCS) Describe(commit string) (string, error) {
+	out, err := g.output("describe", "--tags", "--long", commit)
+	if err != nil {
+		return "", fmt.Errorf("failed to describe %s for %s. err: %v", commit, g.dir, err)
+	}
+	// <tag>-<distance>-g<hash>
+	tokens := strings.Split(strings.TrimSpace(string(out)), "-")
+	if len(tokens) < 3 {
+		return "", fmt.Errorf("Failed to get git describe output for %s", g.dir)
+	}
+	tag := strings.Join(tokens[:len(tokens)-2], "-")
+	if distance := tokens[len(tokens)-2]; distance != "0" {
+		return fmt.Sprintf("%s+%s", tag, distance), nil
+	}
+	return tag, nil
+}
+
+// TagsContaining - git tag --contains
+func (g *GitVCS) TagsContaining(commit string) (map[string]bool, error) {
+	out, err := g.output("tag", "--contains", commit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the tags containing %s for %s. err: %v", commit, g.dir, err)
+	}
+	tags := make(map[string]bool)
+	for _, tag := range strings.Fields(string(out)) {
+		tags[tag] = true
+	}
+	return tags, nil
+}
+
+// HasFile - git cat-file -e <ref>:<name>
+func (g *GitVCS) HasFile(ref, name string) (bool, error) {
+	if _, err := g.ResolveRef(ref); err != nil {
+		return false, err
+	}
+	cmd := exec.Command("git", "-C", g.dir, "cat-file", "-e", fmt.Sprintf("%s:%s", ref, name))
+	g.logger.LogDebug("running command %v", *cmd)
+	return cmd.Run() == nil, nil
+}
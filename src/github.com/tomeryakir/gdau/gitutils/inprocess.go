@@ -0,0 +1,487 @@
+package gitutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// InProcessVCS - a repository read in-process, without running git: its refs, packed-refs and loose and packed objects.
+// Only reading is in-process. The transfer protocols aren't implemented, so Fetch runs the fetch function the repository is given,
+// which for the mirrors is the Fetch of a GitVCS - the git command line.
+type InProcessVCS struct {
+	gitDir  string
+	fetch   func() error
+	store   *objectStore
+	commits map[string]*commitObject
+}
+
+// NewInProcessVCS - the repository at dir (a bare repository, or a working tree with a .git directory); fetch may be nil
+func NewInProcessVCS(dir string, fetch func() error) *InProcessVCS {
+	gitDir := dir
+	if utils.DirExists(filepath.Join(dir, ".git")) {
+		gitDir = filepath.Join(dir, ".git")
+	}
+	return &InProcessVCS{gitDir: gitDir, fetch: fetch, commits: make(map[string]*commitObject)}
+}
+
+// Close - close the pack files
+func (v *InProcessVCS) Close() error {
+	if v.store != nil {
+		v.store.close()
+		v.store = nil
+	}
+	return nil
+}
+
+func (v *InProcessVCS) objects() (*objectStore, error) {
+	if v.store == nil {
+		store, err := openObjectStore(v.gitDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the objects of %s. err: %v", v.gitDir, err)
+		}
+		v.store = store
+	}
+	return v.store, nil
+}
+
+// RemoteURL - the url of the origin (or else downstream) remote in the repository configuration
+func (v *InProcessVCS) RemoteURL() (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(v.gitDir, "config"))
+	if err != nil {
+		return "", fmt.Errorf("failed to get git remote url for %s. err: %v", v.gitDir, err)
+	}
+	urls := make(map[string]string)
+	section := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			section = strings.Trim(line, "[]")
+			continue
+		}
+		tokens := strings.SplitN(line, "=", 2)
+		if len(tokens) == 2 && strings.TrimSpace(tokens[0]) == "url" && strings.HasPrefix(section, "remote ") {
+			urls[utils.ClearQuotes(strings.TrimPrefix(section, "remote "))] = strings.TrimSpace(tokens[1])
+		}
+	}
+	for _, remote := range []string{"origin", "downstream"} {
+		if url, ok := urls[remote]; ok {
+			return url, nil
+		}
+	}
+	return "", fmt.Errorf("failed to get git remote url for %s", v.gitDir)
+}
+
+// Fetch - run the fetch function the repository was given (git fetch, for the mirrors); without one, only check that the repository is there
+func (v *InProcessVCS) Fetch() error {
+	if v.fetch == nil {
+		if !utils.DirExists(filepath.Join(v.gitDir, "objects")) {
+			return fmt.Errorf("%s isn't a git repository, and there's nothing to fetch it with", v.gitDir)
+		}
+		return nil
+	}
+	// new packs may come, and packs may be repacked
+	v.Close()
+	return v.fetch()
+}
+
+// refs - ref name -> object, from the loose refs and packed-refs; the loose ones win
+func (v *InProcessVCS) refs() (map[string]string, error) {
+	refs := make(map[string]string)
+	if data, err := ioutil.ReadFile(filepath.Join(v.gitDir, "packed-refs")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			// "<hash> <ref>"; comments and ^<peeled hash> lines are skipped
+			tokens := strings.Fields(line)
+			if len(tokens) == 2 && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "^") {
+				refs[tokens[1]] = tokens[0]
+			}
+		}
+	}
+	root := filepath.Join(v.gitDir, "refs")
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(v.gitDir, p)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if hash := strings.TrimSpace(string(data)); isHash(hash) {
+			refs[filepath.ToSlash(rel)] = hash
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read the refs of %s. err: %v", v.gitDir, err)
+	}
+	return refs, nil
+}
+
+func isHash(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve - the object a name points at, the way git rev-parse looks names up
+func (v *InProcessVCS) resolve(name string) (string, error) {
+	name = utils.ClearQuotes(name)
+	if isHash(name) {
+		return name, nil
+	}
+	refs, err := v.refs()
+	if err != nil {
+		return "", err
+	}
+	if name == "HEAD" {
+		data, err := ioutil.ReadFile(filepath.Join(v.gitDir, "HEAD"))
+		if err != nil {
+			return "", fmt.Errorf("failed to read HEAD of %s. err: %v", v.gitDir, err)
+		}
+		head := strings.TrimSpace(string(data))
+		if !strings.HasPrefix(head, "ref: ") {
+			return head, nil
+		}
+		name = strings.TrimPrefix(head, "ref: ")
+	}
+	for _, candidate := range []string{name, "refs/" + name, "refs/tags/" + name, "refs/heads/" + name, "refs/remotes/" + name} {
+		if hash, ok := refs[candidate]; ok {
+			return hash, nil
+		}
+	}
+	if len(name) >= 4 && len(name) <= 40 && strings.Trim(strings.ToLower(name), "0123456789abcdef") == "" {
+		store, err := v.objects()
+		if err != nil {
+			return "", err
+		}
+		switch found := store.withPrefix(strings.ToLower(name)); len(found) {
+		case 1:
+			return found[0], nil
+		case 0:
+		default:
+			return "", fmt.Errorf("%s is ambiguous in %s", name, v.gitDir)
+		}
+	}
+	return "", fmt.Errorf("%s wasn't found in %s", name, v.gitDir)
+}
+
+// peel - follow annotated tags to the commit they're for; also returns the date of the outermost tag
+func (v *InProcessVCS) peel(hash string) (string, time.Time, error) {
+	store, err := v.objects()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var tagged time.Time
+	for {
+		typ, data, err := store.read(hash)
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("failed to read %s of %s. err: %v", hash, v.gitDir, err)
+		}
+		switch typ {
+		case objCommit:
+			if _, ok := v.commits[hash]; !ok {
+				c, err := parseCommit(data)
+				if err != nil {
+					return "", time.Time{}, fmt.Errorf("failed to read commit %s of %s. err: %v", hash, v.gitDir, err)
+				}
+				v.commits[hash] = c
+			}
+			return hash, tagged, nil
+		case objTag:
+			tag, err := parseTag(data)
+			if err != nil {
+				return "", time.Time{}, fmt.Errorf("failed to read tag %s of %s. err: %v", hash, v.gitDir, err)
+			}
+			if tagged.IsZero() {
+				tagged = tag.time
+			}
+			hash = tag.object
+		default:
+			return "", time.Time{}, fmt.Errorf("%s of %s isn't a commit", hash, v.gitDir)
+		}
+	}
+}
+
+// commit - a commit, parsed once
+func (v *InProcessVCS) commit(hash string) (*commitObject, error) {
+	if c, ok := v.commits[hash]; ok {
+		return c, nil
+	}
+	if _, _, err := v.peel(hash); err != nil {
+		return nil, err
+	}
+	if c, ok := v.commits[hash]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%s of %s isn't a commit", hash, v.gitDir)
+}
+
+func (v *InProcessVCS) node(hash string) ([]string, time.Time, error) {
+	c, err := v.commit(hash)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return c.parents, c.time, nil
+}
+
+// resolveCommit - the commit a revision points at: a ref or (abbreviated) hash, followed by any of ~N (the N-th first parent ancestor),
+// ^N (the N-th parent) and ^{commit}. The other revision expressions git accepts (e.g. @{...}, :path, ranges) aren't supported.
+func (v *InProcessVCS) resolveCommit(rev string) (string, error) {
+	rev = utils.ClearQuotes(rev)
+	if strings.ContainsAny(rev, ":@") || strings.Contains(rev, "..") {
+		return "", fmt.Errorf("revision %s isn't supported by the in-process backend", rev)
+	}
+	name := rev
+	if i := strings.IndexAny(rev, "~^"); i >= 0 {
+		name = rev[:i]
+	}
+	hash, err := v.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	commit, _, err := v.peel(hash)
+	if err != nil {
+		return "", err
+	}
+	for rest := rev[len(name):]; rest != ""; {
+		op := rest[0]
+		rest = rest[1:]
+		if op == '^' && strings.HasPrefix(rest, "{") {
+			end := strings.Index(rest, "}")
+			if end < 0 || rest[1:end] != "" && rest[1:end] != "commit" {
+				return "", fmt.Errorf("revision %s isn't supported by the in-process backend", rev)
+			}
+			// already peeled to the commit
+			rest = rest[end+1:]
+			continue
+		}
+		digits := len(rest) - len(strings.TrimLeft(rest, "0123456789"))
+		n := 1
+		if digits > 0 {
+			n, _ = strconv.Atoi(rest[:digits])
+			rest = rest[digits:]
+		}
+		if op == '~' {
+			for ; n > 0; n-- {
+				if commit, err = v.parent(commit, 1, rev); err != nil {
+					return "", err
+				}
+			}
+		} else if n > 0 {
+			if commit, err = v.parent(commit, n, rev); err != nil {
+				return "", err
+			}
+		}
+	}
+	// the parents walked to are read as well
+	if _, err := v.commit(commit); err != nil {
+		return "", err
+	}
+	return commit, nil
+}
+
+// parent - the n-th parent of a commit, for the revision rev
+func (v *InProcessVCS) parent(hash string, n int, rev string) (string, error) {
+	c, err := v.commit(hash)
+	if err != nil {
+		return "", err
+	}
+	if n > len(c.parents) {
+		return "", fmt.Errorf("%s wasn't found in %s: %s has %d parents", rev, v.gitDir, hash, len(c.parents))
+	}
+	return c.parents[n-1], nil
+}
+
+// Tags - the tags, oldest first; annotated tags are peeled to their commits, and dated by their tagger
+func (v *InProcessVCS) Tags() ([]Tag, error) {
+	refs, err := v.refs()
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]Tag, 0)
+	for ref, hash := range refs {
+		if !strings.HasPrefix(ref, "refs/tags/") {
+			continue
+		}
+		commit, date, err := v.peel(hash)
+		if err != nil {
+			// e.g. a tag of a tree
+			continue
+		}
+		if date.IsZero() {
+			date = v.commits[commit].time
+		}
+		tags = append(tags, Tag{strings.TrimPrefix(ref, "refs/tags/"), commit, date})
+	}
+	sortTags(tags)
+	return tags, nil
+}
+
+// ResolveRef - the commit a ref points at
+func (v *InProcessVCS) ResolveRef(ref string) (*Commit, error) {
+	hash, err := v.resolveCommit(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the commit of %s for %s. err: %v", ref, v.gitDir, err)
+	}
+	c := v.commits[hash]
+	return &Commit{hash, c.time, c.subject}, nil
+}
+
+// LatestCommit - the commit HEAD points at
+func (v *InProcessVCS) LatestCommit() (*Commit, error) {
+	return v.ResolveRef("HEAD")
+}
+
+// Log - the commits of to that aren't in from, newest first
+func (v *InProcessVCS) Log(from, to string) ([]*Commit, error) {
+	toCommit, err := v.resolveCommit(to)
+	if err != nil {
+		return nil, err
+	}
+	fromCommit := ""
+	if from != "" {
+		if fromCommit, err = v.resolveCommit(from); err != nil {
+			return nil, err
+		}
+	}
+	hashes, err := walkLog(v, fromCommit, toCommit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the log between %s and %s for %s. err: %v", from, to, v.gitDir, err)
+	}
+	commits := make([]*Commit, 0, len(hashes))
+	for _, hash := range hashes {
+		c := v.commits[hash]
+		commits = append(commits, &Commit{hash, c.time, c.subject})
+	}
+	return commits, nil
+}
+
+// IsAncestor - whether ancestor is in the history of commit
+func (v *InProcessVCS) IsAncestor(ancestor, commit string) (bool, error) {
+	a, err := v.resolveCommit(ancestor)
+	if err != nil {
+		return false, err
+	}
+	c, err := v.resolveCommit(commit)
+	if err != nil {
+		return false, err
+	}
+	return reachable(v, a, c)
+}
+
+// files - path -> blob id of the files of a ref
+func (v *InProcessVCS) files(ref string) (map[string]string, error) {
+	hash, err := v.resolveCommit(ref)
+	if err != nil {
+		return nil, err
+	}
+	store, err := v.objects()
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	var walk func(tree, dir string) error
+	walk = func(tree, dir string) error {
+		typ, data, err := store.read(tree)
+		if err != nil {
+			return err
+		}
+		if typ != objTree {
+			return fmt.Errorf("%s isn't a tree", tree)
+		}
+		entries, err := parseTree(data)
+		if err != nil {
+			return fmt.Errorf("failed to read tree %s. err: %v", tree, err)
+		}
+		for _, entry := range entries {
+			name := path.Join(dir, entry.name)
+			switch entry.mode {
+			case "40000":
+				if err := walk(entry.hash, name); err != nil {
+					return err
+				}
+			case "160000":
+				files[name] = gitlinkPrefix + entry.hash
+			case "120000":
+				files[name] = symlinkPrefix + entry.hash
+			default:
+				files[name] = entry.hash
+			}
+		}
+		return nil
+	}
+	if err := walk(v.commits[hash].tree, ""); err != nil {
+		return nil, fmt.Errorf("failed to read the files of %s for %s. err: %v", ref, v.gitDir, err)
+	}
+	return files, nil
+}
+
+// the blob ids of the files that aren't regular files
+const (
+	gitlinkPrefix = "gitlink:"
+	symlinkPrefix = "symlink:"
+)
+
+// blob - the contents of a file as git diffs them; a symlink is its target
+func (v *InProcessVCS) blob(id string) ([]byte, error) {
+	if strings.HasPrefix(id, gitlinkPrefix) {
+		return []byte("Subproject commit " + strings.TrimPrefix(id, gitlinkPrefix) + "\n"), nil
+	}
+	id = strings.TrimPrefix(id, symlinkPrefix)
+	store, err := v.objects()
+	if err != nil {
+		return nil, err
+	}
+	_, data, err := store.read(id)
+	return data, err
+}
+
+// DiffStats - the git diff --shortstat summary between two refs, without rename detection
+func (v *InProcessVCS) DiffStats(from, to string) (string, error) {
+	oldFiles, err := v.files(from)
+	if err != nil {
+		return "", err
+	}
+	newFiles, err := v.files(to)
+	if err != nil {
+		return "", err
+	}
+	return shortStat(oldFiles, newFiles, v.blob)
+}
+
+// Files - the contents of the regular files of a ref whose paths match
+func (v *InProcessVCS) Files(ref string, match func(name string) bool) (map[string][]byte, error) {
+	files, err := v.files(ref)
+	if err != nil {
+		return nil, err
+	}
+	contents := make(map[string][]byte)
+	for name, id := range files {
+		if strings.HasPrefix(id, gitlinkPrefix) || strings.HasPrefix(id, symlinkPrefix) || !match(name) {
+			continue
+		}
+		data, err := v.blob(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s of %s for %s. err: %v", name, ref, v.gitDir, err)
+		}
+		contents[name] = data
+	}
+	return contents, nil
+}
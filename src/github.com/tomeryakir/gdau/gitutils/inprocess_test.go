@@ -0,0 +1,353 @@
+package gitutils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// fixture - a repository built with the git command line, every commit and tag a minute after the one before
+type fixture struct {
+	t    *testing.T
+	dir  string
+	when time.Time
+}
+
+func (f *fixture) git(args ...string) string {
+	f.t.Helper()
+	f.when = f.when.Add(time.Minute)
+	date := f.when.Format(time.RFC3339)
+	cmd := exec.Command("git", append([]string{"-c", "gc.auto=0", "-c", "commit.gpgsign=false", "-c", "tag.gpgsign=false", "-c", "core.autocrlf=false"}, args...)...)
+	cmd.Dir = f.dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a",
+		"GIT_COMMITTER_EMAIL=a@example.com", "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func (f *fixture) write(name, contents string) {
+	f.t.Helper()
+	p := filepath.Join(f.dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// lines - a file big enough for git to store its versions as deltas, with line changed
+func lines(changed int, text string) string {
+	var b strings.Builder
+	for i := 0; i < 300; i++ {
+		if i == changed {
+			fmt.Fprintf(&b, "%s\n", text)
+			continue
+		}
+		fmt.Fprintf(&b, "line %d of a file that changes a little in every commit\n", i)
+	}
+	return b.String()
+}
+
+// newFixture - a history with lightweight, annotated and nested tags, a merged branch, an executable, a symlink and a subdirectory:
+//
+//	one v1.0.0 - two v1.1.0 - three --------- merge v1.2.0 - last (master)
+//	                               \- side -/
+func newFixture(t *testing.T) *fixture {
+	dir, err := ioutil.TempDir("", "gdau-inprocess")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	f := &fixture{t, dir, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	f.git("init", "-q")
+	f.git("symbolic-ref", "HEAD", "refs/heads/master")
+	f.write("big.txt", lines(0, "first"))
+	f.write("a.go", "package a\n\nfunc A() {}\n")
+	f.git("add", "-A")
+	f.git("commit", "-q", "-m", "one")
+	f.git("tag", "v1.0.0")
+
+	f.write("big.txt", lines(100, "second"))
+	f.write("sub/b.go", "package sub\n\nfunc B() {}\n")
+	f.write("run.sh", "#!/bin/sh\necho run\n")
+	if err := os.Chmod(filepath.Join(dir, "run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f.git("add", "-A")
+	f.git("commit", "-q", "-m", "two")
+	f.git("tag", "-a", "v1.1.0", "-m", "release 1.1.0")
+	f.git("tag", "-a", "v1.1.0-nested", "v1.1.0", "-m", "a tag of a tag")
+
+	f.write("big.txt", lines(200, "third"))
+	if err := os.Symlink("a.go", filepath.Join(dir, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+	f.git("add", "-A")
+	f.git("commit", "-q", "-m", "three")
+
+	f.git("checkout", "-q", "-b", "side")
+	f.write("side.txt", "side\n")
+	f.write("a.go", "package a\n\nfunc A() {}\n\nfunc Side() {}\n")
+	f.git("add", "-A")
+	f.git("commit", "-q", "-m", "side")
+	f.git("checkout", "-q", "master")
+	f.write("big.txt", lines(250, "fourth"))
+	f.git("commit", "-q", "-a", "-m", "four")
+	f.git("merge", "-q", "--no-ff", "side", "-m", "merge side")
+	f.git("tag", "-a", "v1.2.0", "-m", "release 1.2.0")
+
+	f.write("big.txt", lines(299, "last"))
+	f.git("rm", "-q", "run.sh")
+	f.git("commit", "-q", "-a", "-m", "last")
+	return f
+}
+
+func TestInProcessVCSMatchesGit(t *testing.T) {
+	layouts := []struct {
+		name  string
+		apply func(f *fixture)
+	}{
+		{"loose objects", func(f *fixture) {}},
+		{"packed, ofs deltas and packed-refs", func(f *fixture) {
+			f.git("gc", "-q")
+		}},
+		{"packed, ref deltas", func(f *fixture) {
+			f.git("-c", "repack.useDeltaBaseOffset=false", "repack", "-a", "-d", "-q", "-f")
+			f.git("pack-refs", "--all")
+		}},
+		{"packed and loose", func(f *fixture) {
+			f.git("gc", "-q")
+			f.write("big.txt", lines(150, "after gc"))
+			f.git("commit", "-q", "-a", "-m", "after gc")
+			f.git("tag", "-a", "v1.3.0", "-m", "release 1.3.0")
+		}},
+	}
+	for _, layout := range layouts {
+		t.Run(layout.name, func(t *testing.T) {
+			f := newFixture(t)
+			layout.apply(f)
+			compareWithGit(t, NewGitVCS(f.dir, "", utils.NewLogger(false)), NewInProcessVCS(f.dir, nil))
+		})
+	}
+}
+
+func compareWithGit(t *testing.T, g *GitVCS, v *InProcessVCS) {
+	defer v.Close()
+	if err := v.Fetch(); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	gitTags, err := g.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := v.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != len(gitTags) {
+		t.Fatalf("Tags = %v, git lists %v", tags, gitTags)
+	}
+	for i := range tags {
+		if tags[i].Name != gitTags[i].Name || tags[i].Commit != gitTags[i].Commit || !tags[i].Date.Equal(gitTags[i].Date) {
+			t.Errorf("tag %d = %v, git lists %v", i, tags[i], gitTags[i])
+		}
+	}
+
+	head, err := g.LatestCommit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs := []string{"HEAD", "master", "side", "refs/heads/side", "v1.0.0", "v1.1.0", "refs/tags/v1.1.0", "v1.1.0-nested", "v1.2.0",
+		head.Hash, head.Hash[:7], "HEAD~1", "HEAD~3", "HEAD^", "v1.2.0^2", "v1.2.0^1~1", "master~2^{commit}", "v1.1.0^{}", "HEAD^^"}
+	for _, ref := range refs {
+		want, err := g.ResolveRef(ref)
+		if err != nil {
+			t.Fatalf("git failed to resolve %s: %v", ref, err)
+		}
+		got, err := v.ResolveRef(ref)
+		if err != nil {
+			t.Errorf("ResolveRef(%s): %v", ref, err)
+			continue
+		}
+		if got.Hash != want.Hash || !got.Time.Equal(want.Time) || got.Subject != want.Subject {
+			t.Errorf("ResolveRef(%s) = %v, git resolves %v", ref, got, want)
+		}
+	}
+	for _, ref := range []string{"missing", "v1.0.0^2", "HEAD~100"} {
+		if c, err := v.ResolveRef(ref); err == nil {
+			t.Errorf("ResolveRef(%s) = %v, want an error", ref, c)
+		}
+	}
+	for _, ref := range []string{"HEAD@{1}", "master:a.go", "v1.0.0..master", "v1.0.0^{tree}"} {
+		if _, err := v.ResolveRef(ref); err == nil || !strings.Contains(err.Error(), "isn't supported") {
+			t.Errorf("ResolveRef(%s) = %v, want an unsupported revision error", ref, err)
+		}
+	}
+	if latest, err := v.LatestCommit(); err != nil || latest.Hash != head.Hash {
+		t.Errorf("LatestCommit = %v, %v, git has %s", latest, err, head.Hash)
+	}
+
+	pairs := [][2]string{{"v1.0.0", "master"}, {"v1.1.0", "v1.2.0"}, {"side", "master"}, {"master", "side"}, {"v1.2.0", "v1.0.0"}, {"HEAD~1", "HEAD"}}
+	for _, p := range pairs {
+		want, err := g.Log(p[0], p[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := v.Log(p[0], p[1])
+		if err != nil {
+			t.Errorf("Log(%s, %s): %v", p[0], p[1], err)
+		} else if !reflect.DeepEqual(hashes(got), hashes(want)) {
+			t.Errorf("Log(%s, %s) = %v, git logs %v", p[0], p[1], hashes(got), hashes(want))
+		}
+
+		wantStat, err := g.DiffStats(p[0], p[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stat, err := v.DiffStats(p[0], p[1]); err != nil || stat != wantStat {
+			t.Errorf("DiffStats(%s, %s) = %q, %v, git says %q", p[0], p[1], stat, err, wantStat)
+		}
+
+		wantAncestor, err := g.IsAncestor(p[0], p[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ancestor, err := v.IsAncestor(p[0], p[1]); err != nil || ancestor != wantAncestor {
+			t.Errorf("IsAncestor(%s, %s) = %v, %v, git says %v", p[0], p[1], ancestor, err, wantAncestor)
+		}
+	}
+	if log, err := v.Log("", "v1.1.0"); err != nil || len(log) != 2 {
+		t.Errorf("Log(, v1.1.0) = %v, %v, want the 2 commits of v1.1.0", log, err)
+	}
+
+	all := func(string) bool { return true }
+	for _, ref := range []string{"v1.0.0", "v1.1.0", "v1.2.0", "master"} {
+		want, err := g.Files(ref, all)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := v.Files(ref, all)
+		if err != nil {
+			t.Errorf("Files(%s): %v", ref, err)
+		} else if !reflect.DeepEqual(got, want) {
+			t.Errorf("Files(%s) has %v, git archives %v", ref, names(got), names(want))
+		}
+	}
+
+	for _, commit := range []string{head.Hash, "side", "v1.1.0~1"} {
+		want, wantErr := DescribeCommit(g, commit)
+		got, err := DescribeCommit(v, commit)
+		if got != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("DescribeCommit(%s) = %s, %v, git describes %s, %v", commit, got, err, want, wantErr)
+		}
+	}
+	for _, commit := range []string{"v1.0.0", "side", head.Hash} {
+		want, err := GetTagsContaining(g, commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := GetTagsContaining(v, commit); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("GetTagsContaining(%s) = %v, %v, git has %v", commit, got, err, want)
+		}
+	}
+}
+
+func hashes(commits []*Commit) []string {
+	h := make([]string, 0, len(commits))
+	for _, c := range commits {
+		h = append(h, c.Hash)
+	}
+	return h
+}
+
+func names(files map[string][]byte) []string {
+	n := make([]string, 0, len(files))
+	for name := range files {
+		n = append(n, name)
+	}
+	return n
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("0123456789abcdef")
+	tests := []struct {
+		name  string
+		delta []byte
+		want  string
+		err   bool
+	}{
+		// source size 16, target size 6, copy 4 bytes at offset 2, insert "xy"
+		{name: "copy and insert", delta: []byte{16, 6, 0x91, 2, 4, 2, 'x', 'y'}, want: "2345xy"},
+		// a copy without size bytes copies 0x10000 bytes, more than the base has
+		{name: "copy past the base", delta: []byte{16, 0x80, 0x80, 0x04, 0x80}, err: true},
+		{name: "wrong source size", delta: []byte{15, 1, 1, 'x'}, err: true},
+		{name: "wrong target size", delta: []byte{16, 3, 1, 'x'}, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyDelta(base, tt.delta)
+			if (err != nil) != tt.err || err == nil && string(got) != tt.want {
+				t.Errorf("applyDelta = %q, %v, want %q (error %v)", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestBaseCache(t *testing.T) {
+	c := newBaseCache(10)
+	p := &pack{}
+	c.add(baseKey{p, 1}, objBlob, []byte("1234"))
+	c.add(baseKey{p, 2}, objBlob, []byte("5678"))
+	// reading 1 makes 2 the least recently used
+	if typ, data, ok := c.get(baseKey{p, 1}); !ok || typ != objBlob || string(data) != "1234" {
+		t.Errorf("get(1) = %d, %q, %v", typ, data, ok)
+	}
+	c.add(baseKey{p, 3}, objTree, []byte("90ab"))
+	if _, _, ok := c.get(baseKey{p, 2}); ok {
+		t.Errorf("the least recently used base wasn't evicted")
+	}
+	if _, _, ok := c.get(baseKey{p, 1}); !ok {
+		t.Errorf("a recently used base was evicted")
+	}
+	c.add(baseKey{p, 4}, objBlob, []byte("more than the limit"))
+	if _, _, ok := c.get(baseKey{p, 4}); ok || c.size != 8 {
+		t.Errorf("a base over the limit was cached, size %d", c.size)
+	}
+}
+
+func TestReadPackedWithBaseCache(t *testing.T) {
+	f := newFixture(t)
+	f.git("gc", "-q")
+	revisions := strings.Fields(f.git("rev-list", "--all"))
+	for _, limit := range []int{deltaBaseCacheLimit, 20000, 0} {
+		store, err := openObjectStore(filepath.Join(f.dir, ".git"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.bases = newBaseCache(limit)
+		for _, revision := range revisions {
+			hash := f.git("rev-parse", revision+":big.txt")
+			typ, data, err := store.read(hash)
+			if err != nil || typ != objBlob || strings.TrimSpace(string(data)) != f.git("cat-file", "blob", hash) {
+				t.Fatalf("limit %d: big.txt of %s = %d, %v, differs from git", limit, revision, typ, err)
+			}
+		}
+		if store.bases.size > limit || limit == deltaBaseCacheLimit && store.bases.order.Len() == 0 {
+			t.Errorf("limit %d: %d bases of %d bytes are cached", limit, store.bases.order.Len(), store.bases.size)
+		}
+		store.close()
+	}
+}
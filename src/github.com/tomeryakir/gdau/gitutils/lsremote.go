@@ -0,0 +1,83 @@
+package gitutils
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// RemoteRefs - the branches and tags a remote advertises, as read by git ls-remote without fetching any objects
+type RemoteRefs struct {
+	Remote string
+	// DefaultBranch - the branch the remote HEAD points at
+	DefaultBranch string
+	Head          string
+	// branch -> head commit
+	Branches map[string]string
+	// tag -> the commit it points at; annotated tags are peeled
+	Tags map[string]string
+}
+
+// ListRemote - read the refs of a remote with git ls-remote
+func ListRemote(remote string, logger *utils.Logger) (*RemoteRefs, error) {
+	cmd := exec.Command("git", "ls-remote", "--symref", remote, "HEAD", "refs/heads/*", "refs/tags/*")
+	cmd.Env = sshEnv()
+	logger.LogDebug("running command %v", *cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run git ls-remote for %s. err: %v", remote, err)
+	}
+	return parseRemoteRefs(remote, string(out)), nil
+}
+
+func parseRemoteRefs(remote, out string) *RemoteRefs {
+	refs := &RemoteRefs{Remote: remote, Branches: make(map[string]string), Tags: make(map[string]string)}
+	peeled := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		tokens := strings.Fields(line)
+		if len(tokens) < 2 {
+			continue
+		}
+		// ref: refs/heads/master	HEAD
+		if tokens[0] == "ref:" && len(tokens) == 3 && tokens[2] == "HEAD" {
+			refs.DefaultBranch = strings.TrimPrefix(tokens[1], "refs/heads/")
+			continue
+		}
+		commit, ref := tokens[0], tokens[1]
+		switch {
+		case ref == "HEAD":
+			refs.Head = commit
+		case strings.HasPrefix(ref, "refs/heads/"):
+			refs.Branches[strings.TrimPrefix(ref, "refs/heads/")] = commit
+		case strings.HasPrefix(ref, "refs/tags/") && strings.HasSuffix(ref, "^{}"):
+			peeled[strings.TrimSuffix(strings.TrimPrefix(ref, "refs/tags/"), "^{}")] = commit
+		case strings.HasPrefix(ref, "refs/tags/"):
+			refs.Tags[strings.TrimPrefix(ref, "refs/tags/")] = commit
+		}
+	}
+	for tag, commit := range peeled {
+		refs.Tags[tag] = commit
+	}
+	if refs.Head == "" && refs.DefaultBranch != "" {
+		refs.Head = refs.Branches[refs.DefaultBranch]
+	}
+	return refs
+}
+
+// ReleaseTags - the semantic version tags of the remote, lowest to highest, ignoring the excluded tags.
+// ls-remote doesn't carry tag dates, so the tags have none and equal versions are ordered by name.
+func (r *RemoteRefs) ReleaseTags(excludedTags []string, tagPrefix string, logger *utils.Logger) ([]*ReleaseTag, []RejectedTag, error) {
+	names := make([]string, 0, len(r.Tags))
+	for tag := range r.Tags {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	listed := make([]listedTag, 0, len(names))
+	for _, name := range names {
+		listed = append(listed, listedTag{name: name})
+	}
+	return releaseTags(r.Remote, listed, excludedTags, tagPrefix, logger)
+}
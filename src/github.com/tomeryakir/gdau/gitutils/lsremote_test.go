@@ -0,0 +1,61 @@
+package gitutils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testLsRemote = `ref: refs/heads/main	HEAD
+3333333333333333333333333333333333333333	HEAD
+3333333333333333333333333333333333333333	refs/heads/main
+4444444444444444444444444444444444444444	refs/heads/develop
+1111111111111111111111111111111111111111	refs/tags/v1.0.0
+aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa	refs/tags/v1.1.0
+2222222222222222222222222222222222222222	refs/tags/v1.1.0^{}
+bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb	refs/tags/not-a-release
+5555555555555555555555555555555555555555	refs/pull/1/head
+`
+
+func TestParseRemoteRefs(t *testing.T) {
+	refs := parseRemoteRefs("https://example.com/lib", testLsRemote)
+	want := &RemoteRefs{
+		Remote:        "https://example.com/lib",
+		DefaultBranch: "main",
+		Head:          "3333333333333333333333333333333333333333",
+		Branches: map[string]string{
+			"main":    "3333333333333333333333333333333333333333",
+			"develop": "4444444444444444444444444444444444444444",
+		},
+		// the annotated tag is peeled to its commit
+		Tags: map[string]string{
+			"v1.0.0":        "1111111111111111111111111111111111111111",
+			"v1.1.0":        "2222222222222222222222222222222222222222",
+			"not-a-release": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+		},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("got %+v, want %+v", refs, want)
+	}
+
+	// without HEAD, e.g. a remote that doesn't advertise it, the default branch's head is used
+	refs = parseRemoteRefs("r", "ref: refs/heads/main\tHEAD\n3333333333333333333333333333333333333333\trefs/heads/main\n")
+	if refs.Head != "3333333333333333333333333333333333333333" {
+		t.Errorf("head = %s, want the head of main", refs.Head)
+	}
+}
+
+func TestRemoteRefsReleaseTags(t *testing.T) {
+	tags, _, err := parseRemoteRefs("r", testLsRemote).ReleaseTags([]string{"v1.0.0"}, "", utils.NewLogger(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"v1.1.0"}) {
+		t.Errorf("release tags = %v, want the allowed semantic versions", names)
+	}
+}
@@ -0,0 +1,201 @@
+package gitutils
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// DefaultCacheDir - where the mirrors are kept unless --cache says otherwise
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory. err: %v", err)
+	}
+	return filepath.Join(dir, "gdau", "mirrors"), nil
+}
+
+// MirrorPath - the directory of the bare mirror of a remote in the cache, e.g. <cache>/github.com/owner/repo.git.
+// The mirror is always inside the cache: empty and . elements of the remote are dropped and .. elements are renamed.
+func MirrorPath(cacheDir, remote string) string {
+	host, repoPath := remoteHostPath(remote)
+	if host == "" {
+		// a local path or file:// url
+		host = "local"
+	}
+	elements := []string{cacheDir}
+	for _, element := range strings.FieldsFunc(host+"/"+repoPath, func(r rune) bool { return r == '/' || r == '\\' }) {
+		switch element {
+		case ".":
+			continue
+		case "..":
+			element = "__"
+		}
+		elements = append(elements, element)
+	}
+	return filepath.Join(elements...) + ".git"
+}
+
+// mirrorLocks - git doesn't take concurrent fetches into one repository, and remotes may be shared by several workers
+var mirrorLocks = struct {
+	sync.Mutex
+	dirs map[string]*sync.Mutex
+}{dirs: make(map[string]*sync.Mutex)}
+
+func mirrorLock(dir string) *sync.Mutex {
+	mirrorLocks.Lock()
+	defer mirrorLocks.Unlock()
+	if _, ok := mirrorLocks.dirs[dir]; !ok {
+		mirrorLocks.dirs[dir] = &sync.Mutex{}
+	}
+	return mirrorLocks.dirs[dir]
+}
+
+// FetchMirror - create a bare mirror of the branches and tags of a remote in dir, or fetch what's new into it.
+// The mirror's HEAD follows the default branch of the remote.
+func FetchMirror(dir, remote string, logger *utils.Logger) error {
+	lock := mirrorLock(dir)
+	lock.Lock()
+	defer lock.Unlock()
+	created := false
+	if !utils.DirExists(dir) {
+		logger.LogDebug("creating a mirror of %s in %s", remote, dir)
+		if err := initMirror(dir, remote, logger); err != nil {
+			os.RemoveAll(dir)
+			return err
+		}
+		created = true
+	} else if err := runGit(dir, logger, "remote", "set-url", "origin", remote); err != nil {
+		return err
+	}
+	cmd := exec.Command("git", "-C", dir, "fetch", "--prune", "--quiet", "origin")
+	cmd.Env = sshEnv()
+	logger.LogDebug("running command %v", *cmd)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if created {
+			// don't leave an empty mirror behind for the next run
+			os.RemoveAll(dir)
+		}
+		return fmt.Errorf("failed to fetch %s into %s.\nout: %v\nerr: %v", remote, dir, string(out), err)
+	}
+	cmd = exec.Command("git", "-C", dir, "ls-remote", "--symref", "origin", "HEAD")
+	cmd.Env = sshEnv()
+	logger.LogDebug("running command %v", *cmd)
+	out, err = cmd.Output()
+	if err != nil {
+		logger.LogInfo("failed to find the default branch of %s. err: %v", remote, err)
+		return nil
+	}
+	if branch := parseRemoteRefs(remote, string(out)).DefaultBranch; branch != "" {
+		return runGit(dir, logger, "symbolic-ref", "HEAD", "refs/heads/"+branch)
+	}
+	return nil
+}
+
+func initMirror(dir, remote string, logger *utils.Logger) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create dir %s. error: %v", dir, err)
+	}
+	steps := [][]string{
+		{"init", "--quiet", "--bare"},
+		{"remote", "add", "origin", remote},
+		// branches and tags only; a --mirror clone would take every ref, e.g. the pull requests of github
+		{"config", "--replace-all", "remote.origin.fetch", "+refs/heads/*:refs/heads/*"},
+		{"config", "--add", "remote.origin.fetch", "+refs/tags/*:refs/tags/*"},
+	}
+	for _, args := range steps {
+		if err := runGit(dir, logger, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runGit - run a local git command in a repository
+func runGit(gitpath string, logger *utils.Logger, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", gitpath}, args...)...)
+	logger.LogDebug("running command %v", *cmd)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to run git %s in %s.\nout: %v\nerr: %v", strings.Join(args, " "), gitpath, string(out), err)
+	}
+	return nil
+}
+
+// Export - write the files of a commit to dir, replacing what's there; the repository itself is left as it is
+func Export(gitpath, commit, dir string, logger *utils.Logger) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s. error: %v", dir, err)
+	}
+	cmd := exec.Command("git", "-C", gitpath, "archive", "--format=tar", commit)
+	logger.LogDebug("running command %v", *cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to archive %s for %s. err: %v", commit, gitpath, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to archive %s for %s. err: %v", commit, gitpath, err)
+	}
+	extractErr := extract(tar.NewReader(stdout), dir)
+	// drain what's left so git doesn't block on a failed extraction
+	io.Copy(ioutil.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to archive %s for %s. err: %v", commit, gitpath, err)
+	}
+	if extractErr != nil {
+		return fmt.Errorf("failed to export %s of %s to %s. error: %v", commit, gitpath, dir, extractErr)
+	}
+	return nil
+}
+
+func extract(reader *tar.Reader, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("%s is outside of the archive", header.Name)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0755)
+		case tar.TypeSymlink:
+			err = os.Symlink(header.Linkname, target)
+		case tar.TypeReg:
+			err = writeFile(reader, target, os.FileMode(header.Mode).Perm())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(reader io.Reader, target string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, reader)
+	return err
+}
@@ -0,0 +1,86 @@
+package gitutils
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+func TestMirrorPath(t *testing.T) {
+	cache := filepath.FromSlash("/cache")
+	tests := []struct {
+		remote string
+		want   string
+	}{
+		{"https://github.com/owner/repo", "/cache/github.com/owner/repo.git"},
+		{"git@github.com:owner/repo.git", "/cache/github.com/owner/repo.git"},
+		{"ssh://git@example.com:2222/team/repo", "/cache/example.com/team/repo.git"},
+		{"/srv/git/repo", "/cache/local/srv/git/repo.git"},
+		{"file:///srv/git/repo.git", "/cache/local/srv/git/repo.git"},
+		// the mirror doesn't leave the cache
+		{"https://example.com/../../etc/repo", "/cache/example.com/__/__/etc/repo.git"},
+		{"git@example.com:../repo", "/cache/example.com/__/repo.git"},
+		{"https://example.com/team/./repo", "/cache/example.com/team/repo.git"},
+		{`https://example.com/team\..\..\repo`, "/cache/example.com/team/__/__/repo.git"},
+		{"https://../repo", "/cache/__/repo.git"},
+	}
+	for _, tt := range tests {
+		if got := MirrorPath(cache, tt.remote); got != filepath.FromSlash(tt.want) {
+			t.Errorf("MirrorPath(%s) = %s, want %s", tt.remote, got, filepath.FromSlash(tt.want))
+		}
+	}
+}
+
+func TestFetchMirror(t *testing.T) {
+	f := newFixture(t)
+	cacheDir := t.TempDir()
+	logger := utils.NewLogger(false)
+	mirror := MirrorPath(cacheDir, f.dir)
+	if err := FetchMirror(mirror, f.dir, logger); err != nil {
+		t.Fatal(err)
+	}
+	refs := func() *RemoteRefs {
+		t.Helper()
+		refs, err := ListRemote(mirror, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return refs
+	}
+	got := refs()
+	if got.DefaultBranch != "master" || got.Branches["master"] != f.git("rev-parse", "master") || got.Branches["side"] != f.git("rev-parse", "side") {
+		t.Errorf("the mirror has branches %v and HEAD at %s", got.Branches, got.DefaultBranch)
+	}
+	if got.Tags["v1.2.0"] != f.git("rev-parse", "v1.2.0^{commit}") || len(got.Tags) != 4 {
+		t.Errorf("the mirror has tags %v", got.Tags)
+	}
+
+	// new commits are fetched, deleted branches pruned, and the mirror follows the new default branch
+	f.git("checkout", "-q", "-b", "main")
+	f.write("new.txt", "new\n")
+	f.git("add", "-A")
+	f.git("commit", "-q", "-m", "new")
+	f.git("tag", "v1.3.0")
+	f.git("branch", "-D", "side")
+	if err := FetchMirror(mirror, f.dir, logger); err != nil {
+		t.Fatal(err)
+	}
+	got = refs()
+	if got.DefaultBranch != "main" || got.Branches["main"] != f.git("rev-parse", "main") || got.Tags["v1.3.0"] == "" {
+		t.Errorf("after a fetch the mirror has branches %v, tags %v and HEAD at %s", got.Branches, got.Tags, got.DefaultBranch)
+	}
+	if _, ok := got.Branches["side"]; ok {
+		t.Errorf("the deleted branch is still in the mirror")
+	}
+
+	// a failed first fetch leaves nothing behind
+	missing := MirrorPath(cacheDir, filepath.Join(f.dir, "missing"))
+	if err := FetchMirror(missing, filepath.Join(f.dir, "missing"), logger); err == nil || utils.DirExists(missing) {
+		t.Errorf("fetching a missing remote gave %v, mirror left: %v", err, utils.DirExists(missing))
+	}
+	if !strings.HasPrefix(missing, cacheDir) {
+		t.Errorf("%s isn't in the cache", missing)
+	}
+}
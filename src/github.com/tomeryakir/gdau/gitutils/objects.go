@@ -0,0 +1,514 @@
+package gitutils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"container/list"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// object types, as pack files number them
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var objectTypes = map[string]int{"commit": objCommit, "tree": objTree, "blob": objBlob, "tag": objTag}
+
+// objectStore - the objects of a repository, loose or in pack files, read without git
+type objectStore struct {
+	dir   string
+	packs []*pack
+	bases *baseCache
+}
+
+// deltaBaseCacheLimit - how many bytes of delta bases are kept; the versions of a file are usually deltas of each other,
+// so reading them one after the other would otherwise rebuild the same bases again and again
+const deltaBaseCacheLimit = 16 << 20
+
+// baseCache - the objects read as delta bases, least recently used first out
+type baseCache struct {
+	limit   int
+	size    int
+	order   *list.List
+	objects map[baseKey]*list.Element
+}
+
+type baseKey struct {
+	pack   *pack
+	offset int64
+}
+
+type cachedBase struct {
+	key  baseKey
+	typ  int
+	data []byte
+}
+
+func newBaseCache(limit int) *baseCache {
+	return &baseCache{limit: limit, order: list.New(), objects: make(map[baseKey]*list.Element)}
+}
+
+func (c *baseCache) get(key baseKey) (int, []byte, bool) {
+	e, ok := c.objects[key]
+	if !ok {
+		return 0, nil, false
+	}
+	c.order.MoveToFront(e)
+	base := e.Value.(*cachedBase)
+	return base.typ, base.data, true
+}
+
+func (c *baseCache) add(key baseKey, typ int, data []byte) {
+	if _, ok := c.objects[key]; ok || len(data) > c.limit {
+		return
+	}
+	c.objects[key] = c.order.PushFront(&cachedBase{key, typ, data})
+	c.size += len(data)
+	for c.size > c.limit {
+		oldest := c.order.Back()
+		base := c.order.Remove(oldest).(*cachedBase)
+		delete(c.objects, base.key)
+		c.size -= len(base.data)
+	}
+}
+
+// pack - a pack file and its version 2 index
+type pack struct {
+	file    *os.File
+	fanout  [256]uint32
+	hashes  []byte
+	offsets []byte
+	large   []byte
+}
+
+func openObjectStore(gitDir string) (*objectStore, error) {
+	store := &objectStore{dir: filepath.Join(gitDir, "objects"), bases: newBaseCache(deltaBaseCacheLimit)}
+	indexes, err := filepath.Glob(filepath.Join(store.dir, "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indexes {
+		p, err := openPack(index)
+		if err != nil {
+			store.close()
+			return nil, err
+		}
+		store.packs = append(store.packs, p)
+	}
+	return store, nil
+}
+
+func (s *objectStore) close() {
+	for _, p := range s.packs {
+		p.file.Close()
+	}
+	s.packs = nil
+	s.bases = newBaseCache(deltaBaseCacheLimit)
+}
+
+func openPack(index string) (*pack, error) {
+	data, err := ioutil.ReadFile(index)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:8], []byte{0xff, 't', 'O', 'c', 0, 0, 0, 2}) {
+		return nil, fmt.Errorf("%s isn't a version 2 pack index", index)
+	}
+	p := &pack{}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(data[8+i*4:])
+	}
+	count := int(p.fanout[255])
+	start := 8 + 256*4
+	// hashes, then a crc32 per object, then the offsets
+	if len(data) < start+count*(20+4+4) {
+		return nil, fmt.Errorf("%s is truncated", index)
+	}
+	p.hashes = data[start : start+count*20]
+	p.offsets = data[start+count*24 : start+count*28]
+	p.large = data[start+count*28:]
+	if p.file, err = os.Open(strings.TrimSuffix(index, ".idx") + ".pack"); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// find - the offset of an object in the pack
+func (p *pack) find(hash []byte) (int64, bool) {
+	lo := 0
+	if hash[0] > 0 {
+		lo = int(p.fanout[hash[0]-1])
+	}
+	hi := int(p.fanout[hash[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool { return bytes.Compare(p.hashes[(lo+i)*20:(lo+i+1)*20], hash) >= 0 })
+	if i >= hi || !bytes.Equal(p.hashes[i*20:(i+1)*20], hash) {
+		return 0, false
+	}
+	offset := binary.BigEndian.Uint32(p.offsets[i*4:])
+	if offset&0x80000000 != 0 {
+		// an index into the 64 bit offsets of packs over 2GB
+		at := int(offset&0x7fffffff) * 8
+		return int64(binary.BigEndian.Uint64(p.large[at:])), true
+	}
+	return int64(offset), true
+}
+
+// withPrefix - the objects of the pack whose hex hash starts with prefix
+func (p *pack) withPrefix(prefix string) []string {
+	found := make([]string, 0)
+	first, err := strconv.ParseUint(prefix[:2], 16, 8)
+	if err != nil {
+		return found
+	}
+	lo := 0
+	if first > 0 {
+		lo = int(p.fanout[first-1])
+	}
+	for i := lo; i < int(p.fanout[first]); i++ {
+		if hash := hex.EncodeToString(p.hashes[i*20 : (i+1)*20]); strings.HasPrefix(hash, prefix) {
+			found = append(found, hash)
+		}
+	}
+	return found
+}
+
+// readPacked - the type and contents of the object at an offset of the pack, with its deltas applied
+func (s *objectStore) readPacked(p *pack, offset int64) (int, []byte, error) {
+	reader := bufio.NewReader(io.NewSectionReader(p.file, offset, 1<<62))
+	c, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	typ := int(c>>4) & 7
+	size := uint64(c & 0x0f)
+	for shift := uint(4); c&0x80 != 0; shift += 7 {
+		if c, err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= uint64(c&0x7f) << shift
+	}
+	var baseType int
+	var base []byte
+	switch typ {
+	case objOfsDelta:
+		if c, err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		distance := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = reader.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			distance = (distance+1)<<7 | int64(c&0x7f)
+		}
+		if baseType, base, err = s.readBase(p, offset-distance); err != nil {
+			return 0, nil, err
+		}
+	case objRefDelta:
+		hash := make([]byte, 20)
+		if _, err = io.ReadFull(reader, hash); err != nil {
+			return 0, nil, err
+		}
+		found := false
+		for _, other := range s.packs {
+			if at, ok := other.find(hash); ok {
+				baseType, base, err = s.readBase(other, at)
+				found = true
+				break
+			}
+		}
+		if !found {
+			baseType, base, err = s.readLoose(hex.EncodeToString(hash))
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	z, err := zlib.NewReader(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer z.Close()
+	data := make([]byte, size)
+	if _, err = io.ReadFull(z, data); err != nil {
+		return 0, nil, err
+	}
+	if typ != objOfsDelta && typ != objRefDelta {
+		return typ, data, nil
+	}
+	patched, err := applyDelta(base, data)
+	return baseType, patched, err
+}
+
+// readBase - the object at an offset of the pack that a delta is applied to, from the cache when it was read before
+func (s *objectStore) readBase(p *pack, offset int64) (int, []byte, error) {
+	key := baseKey{p, offset}
+	if typ, data, ok := s.bases.get(key); ok {
+		return typ, data, nil
+	}
+	typ, data, err := s.readPacked(p, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	s.bases.add(key, typ, data)
+	return typ, data, nil
+}
+
+// applyDelta - rebuild an object from its base and a delta of copy and insert instructions
+func applyDelta(base, delta []byte) ([]byte, error) {
+	size := func() uint64 {
+		var n uint64
+		for shift := uint(0); len(delta) > 0; shift += 7 {
+			c := delta[0]
+			delta = delta[1:]
+			n |= uint64(c&0x7f) << shift
+			if c&0x80 == 0 {
+				break
+			}
+		}
+		return n
+	}
+	if size() != uint64(len(base)) {
+		return nil, fmt.Errorf("delta base size mismatch")
+	}
+	targetSize := size()
+	target := make([]byte, 0, targetSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			// insert the next op bytes
+			if op == 0 || int(op) > len(delta) {
+				return nil, fmt.Errorf("invalid delta instruction")
+			}
+			target = append(target, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+		// copy: the bits of op say which offset and size bytes follow
+		var offset, n uint64
+		for i := uint(0); i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, fmt.Errorf("truncated delta")
+			}
+			if i < 4 {
+				offset |= uint64(delta[0]) << (8 * i)
+			} else {
+				n |= uint64(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if n == 0 {
+			n = 0x10000
+		}
+		if offset+n > uint64(len(base)) {
+			return nil, fmt.Errorf("delta copies past its base")
+		}
+		target = append(target, base[offset:offset+n]...)
+	}
+	if uint64(len(target)) != targetSize {
+		return nil, fmt.Errorf("delta result size mismatch")
+	}
+	return target, nil
+}
+
+// read - the type and contents of an object
+func (s *objectStore) read(hash string) (int, []byte, error) {
+	raw, err := hex.DecodeString(hash)
+	if err != nil || len(raw) != 20 {
+		return 0, nil, fmt.Errorf("invalid object name %s", hash)
+	}
+	for _, p := range s.packs {
+		if offset, ok := p.find(raw); ok {
+			return s.readPacked(p, offset)
+		}
+	}
+	return s.readLoose(hash)
+}
+
+// readLoose - the type and contents of an object stored in a file of its own
+func (s *objectStore) readLoose(hash string) (int, []byte, error) {
+	f, err := os.Open(filepath.Join(s.dir, hash[:2], hash[2:]))
+	if err != nil {
+		return 0, nil, fmt.Errorf("object %s wasn't found", hash)
+	}
+	defer f.Close()
+	z, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer z.Close()
+	data, err := ioutil.ReadAll(z)
+	if err != nil {
+		return 0, nil, err
+	}
+	// <type> <size>\0<contents>
+	nul := bytes.IndexByte(data, 0)
+	space := bytes.IndexByte(data, ' ')
+	if nul < 0 || space < 0 || space > nul {
+		return 0, nil, fmt.Errorf("object %s is corrupt", hash)
+	}
+	typ, ok := objectTypes[string(data[:space])]
+	if !ok {
+		return 0, nil, fmt.Errorf("object %s has an unknown type", hash)
+	}
+	return typ, data[nul+1:], nil
+}
+
+// withPrefix - the objects whose hex hash starts with prefix
+func (s *objectStore) withPrefix(prefix string) []string {
+	found := make(map[string]bool)
+	for _, p := range s.packs {
+		for _, hash := range p.withPrefix(prefix) {
+			found[hash] = true
+		}
+	}
+	if names, err := ioutil.ReadDir(filepath.Join(s.dir, prefix[:2])); err == nil {
+		for _, name := range names {
+			if hash := prefix[:2] + name.Name(); strings.HasPrefix(hash, prefix) {
+				found[hash] = true
+			}
+		}
+	}
+	hashes := make([]string, 0, len(found))
+	for hash := range found {
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
+
+// commitObject - the parts of a commit the analysis reads
+type commitObject struct {
+	tree    string
+	parents []string
+	time    time.Time
+	subject string
+}
+
+func parseCommit(data []byte) (*commitObject, error) {
+	c := &commitObject{}
+	headers, message := splitObject(data)
+	for _, line := range headers {
+		switch {
+		case strings.HasPrefix(line, "tree "):
+			c.tree = line[len("tree "):]
+		case strings.HasPrefix(line, "parent "):
+			c.parents = append(c.parents, line[len("parent "):])
+		case strings.HasPrefix(line, "committer "):
+			t, err := signatureTime(line)
+			if err != nil {
+				return nil, err
+			}
+			c.time = t
+		}
+	}
+	c.subject = strings.SplitN(strings.TrimLeft(message, "\n"), "\n\n", 2)[0]
+	c.subject = strings.Join(strings.Fields(c.subject), " ")
+	return c, nil
+}
+
+// tagObject - the parts of an annotated tag the analysis reads
+type tagObject struct {
+	object     string
+	objectType string
+	time       time.Time
+}
+
+func parseTag(data []byte) (*tagObject, error) {
+	t := &tagObject{}
+	headers, _ := splitObject(data)
+	for _, line := range headers {
+		switch {
+		case strings.HasPrefix(line, "object "):
+			t.object = line[len("object "):]
+		case strings.HasPrefix(line, "type "):
+			t.objectType = line[len("type "):]
+		case strings.HasPrefix(line, "tagger "):
+			when, err := signatureTime(line)
+			if err != nil {
+				return nil, err
+			}
+			t.time = when
+		}
+	}
+	return t, nil
+}
+
+// splitObject - the header lines and the message of a commit or tag; continuation lines (e.g. of signatures) are dropped
+func splitObject(data []byte) ([]string, string) {
+	text := string(data)
+	message := ""
+	if i := strings.Index(text, "\n\n"); i >= 0 {
+		text, message = text[:i], text[i+2:]
+	}
+	headers := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if !strings.HasPrefix(line, " ") {
+			headers = append(headers, line)
+		}
+	}
+	return headers, message
+}
+
+// signatureTime - the time of "committer Name <email> 1700000000 +0200"
+func signatureTime(line string) (time.Time, error) {
+	tokens := strings.Fields(line[strings.LastIndex(line, ">")+1:])
+	if len(tokens) != 2 || len(tokens[1]) != 5 {
+		return time.Time{}, fmt.Errorf("invalid signature %s", line)
+	}
+	seconds, err := strconv.ParseInt(tokens[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid signature %s", line)
+	}
+	hours, err1 := strconv.Atoi(tokens[1][1:3])
+	minutes, err2 := strconv.Atoi(tokens[1][3:5])
+	if err1 != nil || err2 != nil {
+		return time.Time{}, fmt.Errorf("invalid signature %s", line)
+	}
+	offset := hours*3600 + minutes*60
+	if tokens[1][0] == '-' {
+		offset = -offset
+	}
+	return time.Unix(seconds, 0).In(time.FixedZone(tokens[1], offset)), nil
+}
+
+// treeEntry - a file or directory of a tree
+type treeEntry struct {
+	mode string
+	name string
+	hash string
+}
+
+func parseTree(data []byte) ([]treeEntry, error) {
+	entries := make([]treeEntry, 0)
+	for len(data) > 0 {
+		// <mode> <name>\0<20 byte hash>
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || len(data) < nul+21 {
+			return nil, fmt.Errorf("corrupt tree")
+		}
+		entries = append(entries, treeEntry{string(data[:space]), string(data[space+1 : nul]), hex.EncodeToString(data[nul+1 : nul+21])})
+		data = data[nul+21:]
+	}
+	return entries, nil
+}
@@ -0,0 +1,126 @@
+package gitutils
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// IsSSHRemote - whether a remote is an scp-like (git@host:owner/repo) or ssh:// url
+func IsSSHRemote(remote string) bool {
+	return strings.HasPrefix(remote, "ssh://") || strings.HasPrefix(remote, "git+ssh://") || isSCPLike(remote)
+}
+
+func isSCPLike(remote string) bool {
+	colon := strings.Index(remote, ":")
+	at := strings.Index(remote, "@")
+	return !strings.Contains(remote, "://") && at > 0 && colon > at
+}
+
+// remoteHostPath - host and repository path of a remote, without user, port and .git suffix
+func remoteHostPath(remote string) (string, string) {
+	r := strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+	if isSCPLike(r) {
+		r = r[strings.Index(r, "@")+1:]
+		tokens := strings.SplitN(r, ":", 2)
+		return tokens[0], strings.TrimPrefix(tokens[1], "/")
+	}
+	if i := strings.Index(r, "://"); i >= 0 {
+		r = r[i+3:]
+	}
+	tokens := strings.SplitN(r, "/", 2)
+	host := tokens[0]
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	if colon := strings.Index(host, ":"); colon >= 0 {
+		host = host[:colon]
+	}
+	if len(tokens) < 2 {
+		return host, ""
+	}
+	return host, tokens[1]
+}
+
+// BrowseURL - the https page of a remote, e.g. https://github.com/owner/repo for git@github.com:owner/repo.git
+func BrowseURL(remote string) string {
+	if remote == "" {
+		return ""
+	}
+	if !IsSSHRemote(remote) && !strings.HasPrefix(remote, "git://") {
+		return strings.TrimSuffix(remote, ".git")
+	}
+	host, repoPath := remoteHostPath(remote)
+	return "https://" + host + "/" + repoPath
+}
+
+// ImportPathFromRemote - the go import path of a remote, e.g. github.com/owner/repo for git@github.com:owner/repo.git
+func ImportPathFromRemote(remote string) string {
+	host, repoPath := remoteHostPath(remote)
+	return host + "/" + repoPath
+}
+
+// hosts whose import paths are the https url of the repository
+var knownHosts = []string{"github.com", "bitbucket.org", "gitlab.com"}
+
+var goImportMeta = regexp.MustCompile(`<meta\s+name=["']go-import["']\s+content=["']([^"']+)["']`)
+
+// resolvedRemotes - the remotes of the import paths resolved in this run, the entries are grouped by them before they're analyzed
+var resolvedRemotes = struct {
+	sync.Mutex
+	remotes map[string]string
+}{remotes: make(map[string]string)}
+
+// RemoteForImportPath - the git remote of an import path, as its go-import meta tag names it (what go get follows);
+// known hosts and paths without the tag are taken as https urls
+func RemoteForImportPath(importPath string, logger *utils.Logger) (string, error) {
+	for _, host := range knownHosts {
+		if strings.HasPrefix(importPath, host+"/") {
+			return "https://" + importPath, nil
+		}
+	}
+	resolvedRemotes.Lock()
+	remote, ok := resolvedRemotes.remotes[importPath]
+	resolvedRemotes.Unlock()
+	if ok {
+		return remote, nil
+	}
+	remote, err := resolveImportPath(importPath, logger)
+	if err != nil {
+		return "", err
+	}
+	resolvedRemotes.Lock()
+	resolvedRemotes.remotes[importPath] = remote
+	resolvedRemotes.Unlock()
+	return remote, nil
+}
+
+func resolveImportPath(importPath string, logger *utils.Logger) (string, error) {
+	url := fmt.Sprintf("https://%s?go-get=1", importPath)
+	logger.LogDebug("resolving %s from %s", importPath, url)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the remote of %s. err: %v", importPath, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the remote of %s. err: %v", importPath, err)
+	}
+	for _, match := range goImportMeta.FindAllStringSubmatch(string(body), -1) {
+		// <prefix> <vcs> <repo>
+		tokens := strings.Fields(match[1])
+		if len(tokens) == 3 && tokens[1] == "git" && (importPath == tokens[0] || strings.HasPrefix(importPath, tokens[0]+"/")) {
+			return tokens[2], nil
+		}
+	}
+	return "https://" + importPath, nil
+}
@@ -0,0 +1,43 @@
+package gitutils
+
+import "testing"
+
+func TestRemotes(t *testing.T) {
+	tests := []struct {
+		remote     string
+		ssh        bool
+		browse     string
+		importPath string
+	}{
+		{"https://github.com/owner/repo", false, "https://github.com/owner/repo", "github.com/owner/repo"},
+		{"https://github.com/owner/repo.git", false, "https://github.com/owner/repo", "github.com/owner/repo"},
+		{"git@github.com:owner/repo.git", true, "https://github.com/owner/repo", "github.com/owner/repo"},
+		{"git@github.com:/owner/repo", true, "https://github.com/owner/repo", "github.com/owner/repo"},
+		{"ssh://git@example.com:2222/team/repo.git", true, "https://example.com/team/repo", "example.com/team/repo"},
+		{"git+ssh://git@example.com/team/repo", true, "https://example.com/team/repo", "example.com/team/repo"},
+		{"git://example.com/team/repo.git/", false, "https://example.com/team/repo", "example.com/team/repo"},
+		{"", false, "", ""},
+	}
+	for _, tt := range tests {
+		if got := IsSSHRemote(tt.remote); got != tt.ssh {
+			t.Errorf("IsSSHRemote(%s) = %v, want %v", tt.remote, got, tt.ssh)
+		}
+		if got := BrowseURL(tt.remote); got != tt.browse {
+			t.Errorf("BrowseURL(%s) = %s, want %s", tt.remote, got, tt.browse)
+		}
+		if tt.remote == "" {
+			continue
+		}
+		if got := ImportPathFromRemote(tt.remote); got != tt.importPath {
+			t.Errorf("ImportPathFromRemote(%s) = %s, want %s", tt.remote, got, tt.importPath)
+		}
+	}
+}
+
+func TestRemoteForImportPathKnownHosts(t *testing.T) {
+	for _, importPath := range []string{"github.com/owner/repo", "bitbucket.org/owner/repo", "gitlab.com/owner/repo"} {
+		if got, err := RemoteForImportPath(importPath, nil); err != nil || got != "https://"+importPath {
+			t.Errorf("RemoteForImportPath(%s) = %s, %v", importPath, got, err)
+		}
+	}
+}
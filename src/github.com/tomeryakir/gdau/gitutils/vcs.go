@@ -0,0 +1,483 @@
+package gitutils
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// VCS - a repository the dependencies are analyzed in; refs are branches, tags, refs/... names or commit hashes
+type VCS interface {
+	// RemoteURL - the remote the repository is fetched from
+	RemoteURL() (string, error)
+	// Fetch - bring the repository up to date with its remote
+	Fetch() error
+	// Tags - the tags with the commits they point at, oldest first
+	Tags() ([]Tag, error)
+	// ResolveRef - the commit a ref points at
+	ResolveRef(ref string) (*Commit, error)
+	// LatestCommit - the head of the default branch
+	LatestCommit() (*Commit, error)
+	// DiffStats - the summary of the changes between two refs, as git diff --shortstat prints it
+	DiffStats(from, to string) (string, error)
+	// Log - the commits of to that aren't in from, newest first
+	Log(from, to string) ([]*Commit, error)
+	// IsAncestor - whether ancestor is in the history of commit
+	IsAncestor(ancestor, commit string) (bool, error)
+	// Files - the contents of the files of a ref whose paths match
+	Files(ref string, match func(name string) bool) (map[string][]byte, error)
+}
+
+// VCS backends
+const (
+	Git       = "git"
+	InProcess = "inprocess"
+)
+
+// Backends - the accepted VCS backends
+func Backends() []string {
+	return []string{Git, InProcess}
+}
+
+// IsBackend - whether a VCS backend is supported
+func IsBackend(backend string) bool {
+	return backend == Git || backend == InProcess
+}
+
+// NewVCS - the mirror at dir, fetched from remote, read with a backend; the in-process backend still fetches with git
+func NewVCS(backend, dir, remote string, logger *utils.Logger) VCS {
+	cli := NewGitVCS(dir, remote, logger)
+	if backend == InProcess {
+		return NewInProcessVCS(dir, cli.Fetch)
+	}
+	return cli
+}
+
+// Commit - a commit as the analysis sees it
+type Commit struct {
+	Hash    string
+	Time    time.Time
+	Subject string
+}
+
+// Tag - a tag, the commit it points at, and when it was created (the tagger date of annotated tags, the commit date of the others)
+type Tag struct {
+	Name   string
+	Commit string
+	Date   time.Time
+}
+
+// describer - a VCS that describes commits faster than DescribeCommit can with the VCS methods
+type describer interface {
+	Describe(commit string) (string, error)
+}
+
+// containingTagsLister - a VCS that finds the tags containing a commit faster than GetTagsContaining can with the VCS methods
+type containingTagsLister interface {
+	TagsContaining(commit string) (map[string]bool, error)
+}
+
+// fileChecker - a VCS that checks whether a file is in a ref faster than HasFile can with the VCS methods
+type fileChecker interface {
+	HasFile(ref, name string) (bool, error)
+}
+
+// dateLayout - how commit dates are shown in the report, as git log shows them
+const dateLayout = "Mon Jan 2 15:04:05 2006 -0700"
+
+// DateSummary - a commit date as shown in the report, e.g. "Mon Jan 2 15:04:05 2006 +0000 (3 days ago)"
+func DateSummary(t time.Time) string {
+	return fmt.Sprintf("%s (%s)", t.Format(dateLayout), relativeDate(t, time.Now()))
+}
+
+// relativeDate - how long before now t was, the way git prints relative dates
+func relativeDate(t, now time.Time) string {
+	seconds := int64(now.Sub(t) / time.Second)
+	if seconds < 0 {
+		return "in the future"
+	}
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch days := (seconds + 43200) / 86400; {
+	case seconds < 90:
+		return plural(seconds, "second")
+	case seconds < 90*60:
+		return plural((seconds+30)/60, "minute")
+	case seconds < 36*3600:
+		return plural((seconds+1800)/3600, "hour")
+	case days < 14:
+		return plural(days, "day")
+	case days < 70:
+		return plural((days+3)/7, "week")
+	case days < 365:
+		return plural((days+15)/30, "month")
+	default:
+		return plural((days+183)/365, "year")
+	}
+}
+
+// IsTag - whether name is a tag of the repository
+func IsTag(v VCS, name string) bool {
+	_, err := v.ResolveRef("refs/tags/" + name)
+	return err == nil
+}
+
+// IsBranch - whether name is a branch of the repository
+func IsBranch(v VCS, name string) bool {
+	_, err := v.ResolveRef("refs/heads/" + name)
+	return err == nil
+}
+
+// GetBranchHead - the head commit of a branch
+func GetBranchHead(v VCS, branch string) (*Commit, error) {
+	head, err := v.ResolveRef("refs/heads/" + branch)
+	if err != nil {
+		return nil, fmt.Errorf("branch %s wasn't found. err: %v", branch, err)
+	}
+	return head, nil
+}
+
+// CountCommits - the number of commits that are in newcommit but not in oldcommit
+func CountCommits(v VCS, oldcommit, newcommit string) (int, error) {
+	commits, err := v.Log(oldcommit, newcommit)
+	return len(commits), err
+}
+
+// GetCommitLog - the commits between two commits, as "<short hash> <subject>", newest first and at most limit of them;
+// also returns how many were left out
+func GetCommitLog(v VCS, oldcommit, newcommit string, limit int) ([]string, int, error) {
+	commits, err := v.Log(oldcommit, newcommit)
+	if err != nil {
+		return nil, 0, err
+	}
+	subjects := make([]string, 0, len(commits))
+	for _, c := range commits {
+		subjects = append(subjects, fmt.Sprintf("%s %s", shortHash(c.Hash), c.Subject))
+	}
+	if len(subjects) > limit {
+		return subjects[:limit], len(subjects) - limit, nil
+	}
+	return subjects, 0, nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// GetGoFiles - the Go source files of the repository at a commit, without tests, vendor, testdata and internal packages
+func GetGoFiles(v VCS, commit string) (map[string][]byte, error) {
+	return v.Files(commit, isPublicGoFile)
+}
+
+// HasFile - whether a ref has a file at a path, e.g. go.mod
+func HasFile(v VCS, ref, name string) (bool, error) {
+	if c, ok := v.(fileChecker); ok {
+		return c.HasFile(ref, name)
+	}
+	files, err := v.Files(ref, func(path string) bool { return path == name })
+	if err != nil {
+		return false, err
+	}
+	return len(files) > 0, nil
+}
+
+// GetReleaseTags - the semantic version tags, lowest to highest, ignoring the excluded tags.
+// With a tagPrefix (e.g. release-) only tags that start with it are read, and the version follows it.
+func GetReleaseTags(v VCS, excludedTags []string, tagPrefix string, logger *utils.Logger) ([]*ReleaseTag, []RejectedTag, error) {
+	tags, err := v.Tags()
+	if err != nil {
+		return nil, nil, err
+	}
+	source, _ := v.RemoteURL()
+	now := time.Now()
+	listed := make([]listedTag, 0, len(tags))
+	for _, t := range tags {
+		listed = append(listed, listedTag{t.Name, t.Date.Format("2006-01-02 15:04:05 -0700"), relativeDate(t.Date, now)})
+	}
+	return releaseTags(source, listed, excludedTags, tagPrefix, logger)
+}
+
+// DescribeCommit - a commit relative to the nearest tag before it, e.g. v1.3.2+5 for 5 commits after v1.3.2
+func DescribeCommit(v VCS, commit string) (string, error) {
+	if d, ok := v.(describer); ok {
+		return d.Describe(commit)
+	}
+	tags, err := v.Tags()
+	if err != nil {
+		return "", err
+	}
+	best, distance := "", -1
+	for _, tag := range tags {
+		if ok, err := v.IsAncestor(tag.Commit, commit); err != nil || !ok {
+			continue
+		}
+		commits, err := v.Log(tag.Commit, commit)
+		if err != nil {
+			return "", err
+		}
+		// of tags at the same distance the newest wins
+		if distance < 0 || len(commits) <= distance {
+			best, distance = tag.Name, len(commits)
+		}
+	}
+	if distance < 0 {
+		return "", fmt.Errorf("no tag is before %s", commit)
+	}
+	if distance > 0 {
+		return fmt.Sprintf("%s+%d", best, distance), nil
+	}
+	return best, nil
+}
+
+// GetTagsContaining - the tags whose history contains a commit
+func GetTagsContaining(v VCS, commit string) (map[string]bool, error) {
+	if l, ok := v.(containingTagsLister); ok {
+		return l.TagsContaining(commit)
+	}
+	tags, err := v.Tags()
+	if err != nil {
+		return nil, err
+	}
+	containing := make(map[string]bool)
+	for _, tag := range tags {
+		ok, err := v.IsAncestor(commit, tag.Commit)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			containing[tag.Name] = true
+		}
+	}
+	return containing, nil
+}
+
+// commitGraph - the history of a repository that doesn't have git to walk it
+type commitGraph interface {
+	// node - the parents and the commit time of a commit
+	node(hash string) ([]string, time.Time, error)
+}
+
+// walkLog - the commits reachable from to but not from from (when given), newest first, as git log orders them
+func walkLog(g commitGraph, from, to string) ([]string, error) {
+	excluded := make(map[string]bool)
+	if from != "" {
+		stack := []string{from}
+		for len(stack) > 0 {
+			hash := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if excluded[hash] {
+				continue
+			}
+			excluded[hash] = true
+			parents, _, err := g.node(hash)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, parents...)
+		}
+	}
+	log := make([]string, 0)
+	seen := make(map[string]bool)
+	queue := &byTime{}
+	push := func(hash string) error {
+		if seen[hash] || excluded[hash] {
+			return nil
+		}
+		seen[hash] = true
+		_, t, err := g.node(hash)
+		if err != nil {
+			return err
+		}
+		heap.Push(queue, timedHash{hash, t})
+		return nil
+	}
+	if err := push(to); err != nil {
+		return nil, err
+	}
+	for queue.Len() > 0 {
+		next := heap.Pop(queue).(timedHash)
+		log = append(log, next.hash)
+		parents, _, err := g.node(next.hash)
+		if err != nil {
+			return nil, err
+		}
+		for _, parent := range parents {
+			if err := push(parent); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return log, nil
+}
+
+// reachable - whether ancestor is in the history of commit
+func reachable(g commitGraph, ancestor, commit string) (bool, error) {
+	seen := make(map[string]bool)
+	stack := []string{commit}
+	for len(stack) > 0 {
+		hash := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if hash == ancestor {
+			return true, nil
+		}
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		parents, _, err := g.node(hash)
+		if err != nil {
+			return false, err
+		}
+		stack = append(stack, parents...)
+	}
+	return false, nil
+}
+
+type timedHash struct {
+	hash string
+	time time.Time
+}
+
+// byTime - a heap of commits, newest first
+type byTime []timedHash
+
+func (h byTime) Len() int            { return len(h) }
+func (h byTime) Less(i, j int) bool  { return h[i].time.After(h[j].time) }
+func (h byTime) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *byTime) Push(x interface{}) { *h = append(*h, x.(timedHash)) }
+func (h *byTime) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// sortTags - order tags oldest first, by name when they're as old
+func sortTags(tags []Tag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		if !tags[i].Date.Equal(tags[j].Date) {
+			return tags[i].Date.Before(tags[j].Date)
+		}
+		return tags[i].Name < tags[j].Name
+	})
+}
+
+// shortStat - the git diff --shortstat summary of the changes between two trees, given as path -> blob id;
+// read loads a blob. Renames count as a deletion and an addition.
+func shortStat(oldFiles, newFiles map[string]string, read func(id string) ([]byte, error)) (string, error) {
+	paths := make([]string, 0)
+	for name, id := range oldFiles {
+		if newFiles[name] != id {
+			paths = append(paths, name)
+		}
+	}
+	for name := range newFiles {
+		if _, ok := oldFiles[name]; !ok {
+			paths = append(paths, name)
+		}
+	}
+	if len(paths) == 0 {
+		return "", nil
+	}
+	insertions, deletions := 0, 0
+	for _, name := range paths {
+		oldContents, newContents := []byte{}, []byte{}
+		var err error
+		if id, ok := oldFiles[name]; ok {
+			if oldContents, err = read(id); err != nil {
+				return "", err
+			}
+		}
+		if id, ok := newFiles[name]; ok {
+			if newContents, err = read(id); err != nil {
+				return "", err
+			}
+		}
+		if isBinary(oldContents) || isBinary(newContents) {
+			continue
+		}
+		a, b := splitLines(oldContents), splitLines(newContents)
+		edits := editDistance(a, b)
+		insertions += (edits + len(b) - len(a)) / 2
+		deletions += (edits - len(b) + len(a)) / 2
+	}
+	// the wording of git's print_stat_summary
+	stat := fmt.Sprintf(" %d file%s changed", len(paths), pluralS(len(paths)))
+	if insertions > 0 || deletions == 0 {
+		stat += fmt.Sprintf(", %d insertion%s(+)", insertions, pluralS(insertions))
+	}
+	if deletions > 0 || insertions == 0 {
+		stat += fmt.Sprintf(", %d deletion%s(-)", deletions, pluralS(deletions))
+	}
+	return stat, nil
+}
+
+func pluralS(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// isBinary - git's check: a NUL in the first 8000 bytes
+func isBinary(contents []byte) bool {
+	if len(contents) > 8000 {
+		contents = contents[:8000]
+	}
+	for _, c := range contents {
+		if c == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines - the lines of a file with their line ends, so a missing newline at the end is a change too
+func splitLines(contents []byte) []string {
+	lines := strings.SplitAfter(string(contents), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// editDistance - the fewest line insertions and deletions that turn a into b (Myers' algorithm)
+func editDistance(a, b []string) int {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return 0
+	}
+	v := make([]int, 2*max+2)
+	for d := 0; d <= max; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[max+k-1] < v[max+k+1] {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				return d
+			}
+		}
+	}
+	return max
+}
@@ -0,0 +1,188 @@
+package parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	git "github.com/tomeryakir/gdau/gitutils"
+	"github.com/tomeryakir/gdau/toml"
+	"github.com/tomeryakir/gdau/utils"
+	"github.com/tomeryakir/gdau/yaml"
+)
+
+// format - a supported dependency file format: how to recognize it and how to build its parser
+type format struct {
+	name      string
+	fileNames []string
+	sniff     func(contents string) bool
+	newParser func(gitRoot, depPath string, logger *utils.Logger) Parser
+}
+
+// formats - every supported format; new formats only need to be added here
+var formats = []format{
+	{"gpm", []string{"Godeps"}, sniffGPM, func(gitRoot, depPath string, logger *utils.Logger) Parser {
+		return NewGPMParser(gitRoot, depPath, logger)
+	}},
+	{"dep", []string{"Gopkg.toml"}, sniffGopkg, func(gitRoot, depPath string, logger *utils.Logger) Parser {
+		return NewGopkgParser(gitRoot, depPath, logger)
+	}},
+	{"module", []string{"go.mod"}, sniffGoMod, func(gitRoot, depPath string, logger *utils.Logger) Parser {
+		return NewGoModParser(gitRoot, depPath, logger)
+	}},
+	{"godep", []string{"Godeps.json"}, sniffGodep, func(gitRoot, depPath string, logger *utils.Logger) Parser {
+		return NewGodepParser(gitRoot, depPath, logger)
+	}},
+	{"glide", []string{"glide.yaml", "glide.yml"}, sniffGlide, func(gitRoot, depPath string, logger *utils.Logger) Parser {
+		return NewGlideParser(gitRoot, depPath, logger)
+	}},
+	{"govendor", []string{"vendor.json"}, sniffGovendor, func(gitRoot, depPath string, logger *utils.Logger) Parser {
+		return NewGovendorParser(gitRoot, depPath, logger)
+	}},
+}
+
+// lock files point at the manifest next to them
+var lockFiles = map[string]string{
+	gopkgLockFile: "Gopkg.toml",
+	glideLockFile: "glide.yaml",
+	"go.sum":      "go.mod",
+}
+
+// FormatNames - the names accepted by --deptype
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.name)
+	}
+	return names
+}
+
+// NewParser - the parser for depPath; tipe forces a format, otherwise it's detected from the file name and content
+func NewParser(tipe, gitRoot, depPath string, logger *utils.Logger) (Parser, error) {
+	if manifest, ok := lockFiles[path.Base(depPath)]; ok {
+		depPath = path.Join(path.Dir(depPath), manifest)
+		logger.LogInfo("using manifest %s", depPath)
+	}
+	if tipe != "" {
+		for _, f := range formats {
+			if f.name == tipe {
+				return f.newParser(gitRoot, depPath, logger), nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported dependency format %s (supported: %s)", tipe, strings.Join(FormatNames(), ", "))
+	}
+	f, err := DetectFormat(depPath, utils.ReadFileContents(depPath, logger))
+	if err != nil {
+		return nil, err
+	}
+	logger.LogInfo("detected %s format for %s", f, depPath)
+	return NewParser(f, gitRoot, depPath, logger)
+}
+
+// DetectFormat - the format name of a dependency file; the file name and the content have to agree
+func DetectFormat(depPath, contents string) (string, error) {
+	byName := ""
+	for _, f := range formats {
+		for _, name := range f.fileNames {
+			if path.Base(depPath) == name {
+				byName = f.name
+			}
+		}
+	}
+	byContent := make([]string, 0)
+	for _, f := range formats {
+		if f.sniff(contents) {
+			byContent = append(byContent, f.name)
+		}
+	}
+	sort.Strings(byContent)
+	switch {
+	case byName != "" && (len(byContent) == 0 || contains(byContent, byName)):
+		return byName, nil
+	case byName != "":
+		return "", fmt.Errorf("%s is named like a %s file but its content looks like %s; use --deptype to choose", depPath, byName, strings.Join(byContent, " or "))
+	case len(byContent) == 1:
+		return byContent[0], nil
+	case len(byContent) > 1:
+		return "", fmt.Errorf("%s is ambiguous, it could be %s; use --deptype to choose", depPath, strings.Join(byContent, " or "))
+	}
+	return "", fmt.Errorf("%s isn't a supported dependency file (supported: %s)", depPath, strings.Join(FormatNames(), ", "))
+}
+
+func contains(s []string, v string) bool {
+	for _, sv := range s {
+		if sv == v {
+			return true
+		}
+	}
+	return false
+}
+
+// meaningfulLines - lines that aren't empty or comments
+func meaningfulLines(contents, comment string) []string {
+	lines := make([]string, 0)
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, comment) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func sniffGPM(contents string) bool {
+	lines := meaningfulLines(contents, "#")
+	for _, line := range lines {
+		tokens := strings.Fields(line)
+		if len(tokens) < 2 || len(tokens) > 3 || !strings.Contains(tokens[0], ".") {
+			return false
+		}
+		if strings.ContainsAny(tokens[1], "=:{}[]\"") || !git.IsSSHRemote(tokens[0]) && strings.ContainsAny(tokens[0], "=:{}[]\"") {
+			return false
+		}
+	}
+	return len(lines) > 0
+}
+
+func sniffGoMod(contents string) bool {
+	lines := meaningfulLines(contents, "//")
+	return len(lines) > 0 && strings.HasPrefix(lines[0], "module ")
+}
+
+func sniffGopkg(contents string) bool {
+	doc, err := toml.Parse(contents)
+	if err != nil {
+		return false
+	}
+	return len(doc.ArrayTables("constraint")) > 0 || len(doc.ArrayTables("override")) > 0 || doc.Table("prune") != nil || len(doc.Root.Keys) > 0 && doc.Root.Keys[0] == "required"
+}
+
+func sniffGodep(contents string) bool {
+	g := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(contents), &g); err != nil {
+		return false
+	}
+	_, hasDeps := g["Deps"]
+	_, hasImportPath := g["ImportPath"]
+	return hasDeps && hasImportPath
+}
+
+func sniffGovendor(contents string) bool {
+	v := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(contents), &v); err != nil {
+		return false
+	}
+	_, hasPackage := v["package"]
+	_, hasRootPath := v["rootPath"]
+	return hasPackage && hasRootPath
+}
+
+func sniffGlide(contents string) bool {
+	doc, err := yaml.Parse(contents)
+	if err != nil || doc.Root.Kind != yaml.Mapping {
+		return false
+	}
+	return doc.Root.Get("package") != nil && (doc.Root.Get("import") != nil || doc.Root.Get("testImport") != nil)
+}
@@ -0,0 +1,104 @@
+package parsers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testGPM = `# pinned
+github.com/a/lib v1.0.0
+git@example.com:team/private.git 1111111111111111111111111111111111111111
+`
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		depPath  string
+		contents string
+		want     string
+		err      string
+	}{
+		{"Godeps", testGPM, "gpm", ""},
+		{"Gopkg.toml", testGopkgToml, "dep", ""},
+		{"go.mod", testGoMod, "module", ""},
+		{"Godeps/Godeps.json", testGodeps, "godep", ""},
+		{"glide.yaml", testGlideYaml, "glide", ""},
+		{"glide.yml", testGlideYaml, "glide", ""},
+		{"vendor/vendor.json", testVendorJSON, "govendor", ""},
+		// a name alone is enough when the content doesn't look like anything
+		{"Godeps", "", "gpm", ""},
+		// the content decides files with other names
+		{"deps.txt", testGPM, "gpm", ""},
+		{"deps.json", testGodeps, "godep", ""},
+		{"deps.yaml", testGlideYaml, "glide", ""},
+		{"Godeps", testGodeps, "", "named like a gpm file but its content looks like godep"},
+		{"deps.txt", "not a dependency file = at all", "", "isn't a supported dependency file"},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.depPath, tt.contents)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("DetectFormat(%s) = %s, %v, want an error containing %q", tt.depPath, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("DetectFormat(%s) = %s, %v, want %s", tt.depPath, got, err, tt.want)
+		}
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	dir := writeFixture(t, map[string]string{
+		"Gopkg.toml":   testGopkgToml,
+		"Gopkg.lock":   testGopkgLock,
+		"deps.txt":     testGPM,
+		"glide.yaml":   testGlideYaml,
+		"glide.lock":   testGlideLock,
+		"other/go.mod": testGoMod,
+	})
+	logger := utils.NewLogger(false)
+	tests := []struct {
+		tipe    string
+		depPath string
+		want    string
+	}{
+		// a lock file is replaced by its manifest
+		{"", "Gopkg.lock", "Gopkg.toml"},
+		{"", "glide.lock", "glide.yaml"},
+		{"", "other/go.mod", "other/go.mod"},
+		// a forced format isn't checked against the file
+		{"gpm", "deps.txt", "deps.txt"},
+		{"godep", "deps.txt", "deps.txt"},
+	}
+	for _, tt := range tests {
+		p, err := NewParser(tt.tipe, dir, filepath.Join(dir, filepath.FromSlash(tt.depPath)), logger)
+		if err != nil {
+			t.Errorf("NewParser(%q, %s) failed: %v", tt.tipe, tt.depPath, err)
+			continue
+		}
+		if want := filepath.Join(dir, filepath.FromSlash(tt.want)); p.DepPath() != want || p.GitRoot() != dir {
+			t.Errorf("NewParser(%q, %s) reads %s in %s, want %s in %s", tt.tipe, tt.depPath, p.DepPath(), p.GitRoot(), want, dir)
+		}
+	}
+	if _, ok := mustParser(t, "godep", filepath.Join(dir, "deps.txt")).(*GodepParser); !ok {
+		t.Errorf("--deptype godep didn't build a godep parser")
+	}
+	if _, ok := mustParser(t, "", filepath.Join(dir, "deps.txt")).(*GPMParser); !ok {
+		t.Errorf("deps.txt wasn't detected as a gpm file")
+	}
+	if _, err := NewParser("bundler", dir, filepath.Join(dir, "deps.txt"), logger); err == nil || !strings.Contains(err.Error(), "supported: "+strings.Join(FormatNames(), ", ")) {
+		t.Errorf("an unknown format gave %v, want the supported formats listed", err)
+	}
+}
+
+func mustParser(t *testing.T, tipe, depPath string) Parser {
+	t.Helper()
+	p, err := NewParser(tipe, filepath.Dir(depPath), depPath, utils.NewLogger(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
@@ -0,0 +1,55 @@
+package parsers
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const (
+	govendorFile = "vendor.json"
+)
+
+// DiscoverManifests - every supported dependency file under root; vendored code and hidden directories are not searched
+func DiscoverManifests(root string, logger *utils.Logger) ([]string, error) {
+	fileNames := make(map[string]bool)
+	for _, f := range formats {
+		for _, name := range f.fileNames {
+			fileNames[name] = true
+		}
+	}
+	manifests := make([]string, 0)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name := info.Name()
+		if !info.IsDir() {
+			if fileNames[name] && name != govendorFile {
+				logger.LogDebug("found manifest %s", p)
+				manifests = append(manifests, p)
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "node_modules" {
+			return filepath.SkipDir
+		}
+		if name == "vendor" {
+			// only govendor's own file, not the manifests of vendored packages
+			if vendorFile := filepath.Join(p, govendorFile); utils.DirExists(vendorFile) {
+				logger.LogDebug("found manifest %s", vendorFile)
+				manifests = append(manifests, vendorFile)
+			}
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	sort.Strings(manifests)
+	return manifests, err
+}
@@ -0,0 +1,38 @@
+package parsers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+func TestDiscoverManifests(t *testing.T) {
+	dir := writeFixture(t, map[string]string{
+		"go.mod":                              testGoMod,
+		"go.sum":                              "",
+		"tools/Gopkg.toml":                    testGopkgToml,
+		"tools/Gopkg.lock":                    testGopkgLock,
+		"legacy/Godeps/Godeps.json":           testGodeps,
+		"legacy/vendor/vendor.json":           testVendorJSON,
+		"legacy/vendor/github.com/a/b/go.mod": testGoMod,
+		"web/glide.yaml":                      testGlideYaml,
+		"web/node_modules/x/go.mod":           testGoMod,
+		"testdata/go.mod":                     testGoMod,
+		".hidden/go.mod":                      testGoMod,
+		"_old/Godeps":                         testGPM,
+		"vendor.json":                         testVendorJSON,
+	})
+	got, err := DiscoverManifests(dir, utils.NewLogger(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"go.mod", "legacy/Godeps/Godeps.json", "legacy/vendor/vendor.json", "tools/Gopkg.toml", "web/glide.yaml"}
+	for i := range want {
+		want[i] = filepath.Join(dir, filepath.FromSlash(want[i]))
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,227 @@
+package parsers
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tomeryakir/gdau/semver"
+	"github.com/tomeryakir/gdau/utils"
+	"github.com/tomeryakir/gdau/yaml"
+)
+
+const (
+	glideLockFile = "glide.lock"
+)
+
+type GlideParser struct {
+	gitRoot string
+	depPath string
+	logger  *utils.Logger
+}
+
+func NewGlideParser(gitRoot, depPath string, logger *utils.Logger) *GlideParser {
+	return &GlideParser{gitRoot, depPath, logger}
+}
+
+func (p *GlideParser) GitRoot() string {
+	return p.gitRoot
+}
+
+func (p *GlideParser) DepPath() string {
+	return p.depPath
+}
+
+// LockPath - the glide.lock that sits next to the glide.yaml
+func (p *GlideParser) LockPath() string {
+	return path.Join(path.Dir(p.depPath), glideLockFile)
+}
+
+func (p *GlideParser) parseYaml(filePath, contents string) *yaml.Document {
+	doc, err := yaml.Parse(contents)
+	if err != nil {
+		p.logger.PanicWithMessage("failed to parse %s. error: %v", filePath, err)
+	}
+	return doc
+}
+
+// readLock - the parsed glide.lock, or nil if there's none
+func (p *GlideParser) readLock() *yaml.Document {
+	if !utils.DirExists(p.LockPath()) {
+		p.logger.LogInfo("%s wasn't found, only %s will be analyzed", p.LockPath(), p.depPath)
+		return nil
+	}
+	return p.parseYaml(p.LockPath(), utils.ReadFileContents(p.LockPath(), p.logger))
+}
+
+// glidePackages - the package items of both the regular and the test section
+func glidePackages(root *yaml.Node, sections ...string) []*yaml.Node {
+	packages := make([]*yaml.Node, 0)
+	for _, section := range sections {
+		for _, item := range root.List(section) {
+			if item.Kind == yaml.Mapping {
+				packages = append(packages, item)
+			}
+		}
+	}
+	return packages
+}
+
+// glideConstraintKind - what a glide.yaml `version` is: a commit, a semver range/tag, or a branch
+func glideConstraintKind(version string) string {
+	if version == "" {
+		return ""
+	}
+	if len(version) == 40 && isHexString(version) {
+		return "revision"
+	}
+	if _, err := semver.Satisfies(version, "0.0.0"); err == nil {
+		return "version"
+	}
+	return "branch"
+}
+
+func (p *GlideParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[string]string, map[string]*Entry) {
+	entries := make([]*Entry, 0)
+	contents := utils.ReadFileContents(godepsPath, p.logger)
+	p.logger.LogDebug("got file contents %s", contents)
+	m := make(map[string]string)
+	me := make(map[string]*Entry)
+	doc := p.parseYaml(godepsPath, contents)
+
+	for _, pkg := range glidePackages(doc.Root, "import", "testImport") {
+		name := pkg.String("package")
+		if name == "" {
+			continue
+		}
+		entry := &Entry{Path: name, IsUpdated: true}
+		entry.setRemote(pkg.String("repo"))
+		entry.Constraint = pkg.String("version")
+		entry.ConstraintKind = glideConstraintKind(entry.Constraint)
+		entry.SubPackages = pkg.Strings("subpackages")
+		entry.CommitVersion = entry.Constraint
+		switch entry.ConstraintKind {
+		case "revision":
+			entry.GitType = Commit
+		case "branch":
+			entry.GitType = Branch
+			entry.Branch = entry.Constraint
+		default:
+			entry.GitType = Tag
+			if isRange(entry.Constraint) {
+				// the release the lock is at is found among the tags in the range
+				entry.VersionRange = entry.Constraint
+			} else {
+				entry.CommitVersion = exactVersion(entry.Constraint)
+			}
+		}
+		me[name] = entry
+		entries = append(entries, entry)
+	}
+
+	lock := p.readLock()
+	if lock != nil {
+		for _, pkg := range glidePackages(lock.Root, "imports", "testImports") {
+			name := pkg.String("name")
+			entry, ok := me[name]
+			if !ok {
+				// pulled in by another dependency
+				entry = &Entry{Path: name, IsUpdated: true, IsIndirect: true}
+				entry.setRemote(pkg.String("repo"))
+				entry.SubPackages = pkg.Strings("subpackages")
+				me[name] = entry
+				entries = append(entries, entry)
+			}
+			// glide.lock always pins a commit; a version constraint keeps its tag or range as the version
+			entry.LockedRevision = pkg.String("version")
+			if entry.ConstraintKind != "version" {
+				entry.GitType = Commit
+				entry.CommitVersion = entry.LockedRevision
+			}
+		}
+	}
+
+	skipUnlockedRanges(entries, glideLockFile)
+	return entries, contents, m, me
+}
+
+// glideConstraintAllows - like constraintAllows, except a plain glide version pins that exact tag
+func glideConstraintAllows(entry *Entry) bool {
+	if entry.ConstraintKind == "version" && !isRange(entry.Constraint) {
+		return strings.TrimPrefix(exactVersion(entry.Constraint), "v") == strings.TrimPrefix(entry.NewCommitVersion, "v")
+	}
+	return constraintAllows(entry)
+}
+
+// exactVersion - the version a constraint that isn't a range names; = names one version (=v1.2.3), like a plain version does
+func exactVersion(constraint string) string {
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(constraint), "="))
+}
+
+func isRange(constraint string) bool {
+	return strings.ContainsAny(exactVersion(constraint), "^~<>=*xX|, ")
+}
+
+// skipUnlockedRanges - skip the entries constrained by a version range that the lock file doesn't pin; a range names no version to compare
+func skipUnlockedRanges(entries []*Entry, lockFile string) {
+	for _, entry := range entries {
+		if entry.ConstraintKind == "version" && entry.LockedRevision == "" && isRange(entry.Constraint) {
+			entry.IsSkipped = true
+			entry.Summary = fmt.Sprintf("version range %s isn't locked in %s", entry.Constraint, lockFile)
+		}
+	}
+}
+
+func (p *GlideParser) UpdateFile(entries []*Entry, content string, contentMap map[string]string, entryMap map[string]*Entry) {
+	p.updateLock(entryMap)
+	doc := p.parseYaml(p.DepPath(), content)
+	for _, pkg := range glidePackages(doc.Root, "import", "testImport") {
+		entry, ok := entryMap[pkg.String("package")]
+		if !ok || !ShouldUpdate(entry) {
+			continue
+		}
+		version := pkg.Get("version")
+		if version == nil || version.Kind != yaml.Scalar {
+			continue
+		}
+		if entry.LockedRevision != "" && glideConstraintAllows(entry) {
+			continue
+		}
+		switch entry.ConstraintKind {
+		case "version":
+			p.logger.LogInfo("updating constraint of %s to %s", entry.Path, entry.NewCommitVersion)
+			doc.SetScalar(version, bumpConstraint(version.Value, entry.NewCommitVersion))
+		case "revision":
+			p.logger.LogInfo("updating constraint of %s to %s", entry.Path, entry.NewRevision)
+			doc.SetScalar(version, entry.NewRevision)
+		}
+	}
+	if doc.IsModified() {
+		p.logger.LogDebug("content is now:ֿ\n%s", doc.String())
+		p.logger.LogInfo("Updating file")
+		utils.WriteFile(p.DepPath(), doc.String(), p.logger)
+	} else {
+		p.logger.LogInfo("File already updated")
+	}
+}
+
+// updateLock - pin the outdated packages of glide.lock to their new revision
+func (p *GlideParser) updateLock(entryMap map[string]*Entry) {
+	lock := p.readLock()
+	if lock == nil {
+		return
+	}
+	for _, pkg := range glidePackages(lock.Root, "imports", "testImports") {
+		entry, ok := entryMap[pkg.String("name")]
+		if !ok || !ShouldUpdate(entry) || entry.NewRevision == "" {
+			continue
+		}
+		if version := pkg.Get("version"); version != nil && version.Kind == yaml.Scalar {
+			lock.SetScalar(version, entry.NewRevision)
+		}
+	}
+	if lock.IsModified() {
+		p.logger.LogInfo("Updating lock file; run `glide up` to refresh its hash")
+		utils.WriteFile(p.LockPath(), lock.String(), p.logger)
+	}
+}
@@ -0,0 +1,98 @@
+package parsers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testGlideYaml = `package: example.com/app
+import:
+- package: github.com/a/range
+  version: ^0.8.0
+- package: github.com/a/tag
+  version: v1.2.0
+- package: github.com/a/plain
+  version: 1.2.0
+- package: github.com/a/branch
+  version: develop
+- package: github.com/a/unpinned
+`
+
+const testGlideLock = `hash: 0000
+imports:
+- name: github.com/a/range
+  version: 1111111111111111111111111111111111111111
+- name: github.com/a/tag
+  version: 2222222222222222222222222222222222222222
+- name: github.com/a/plain
+  version: 6666666666666666666666666666666666666666
+- name: github.com/a/branch
+  version: 3333333333333333333333333333333333333333
+- name: github.com/a/unpinned
+  version: 4444444444444444444444444444444444444444
+- name: github.com/a/indirect
+  version: 5555555555555555555555555555555555555555
+`
+
+func writeGlideFiles(t *testing.T) string {
+	dir := writeFixture(t, map[string]string{"glide.yaml": testGlideYaml, "glide.lock": testGlideLock})
+	return filepath.Join(dir, "glide.yaml")
+}
+
+func TestGlideReadFile(t *testing.T) {
+	depPath := writeGlideFiles(t)
+	p := NewGlideParser(filepath.Dir(depPath), depPath, utils.NewLogger(false))
+	_, _, _, entries := p.ReadFile(filepath.Dir(depPath), depPath)
+	tests := []struct {
+		path         string
+		gitType      EntryType
+		version      string
+		versionRange string
+		locked       string
+	}{
+		{"github.com/a/range", Tag, "^0.8.0", "^0.8.0", strings.Repeat("1", 40)},
+		{"github.com/a/tag", Tag, "v1.2.0", "", strings.Repeat("2", 40)},
+		{"github.com/a/plain", Tag, "1.2.0", "", strings.Repeat("6", 40)},
+		{"github.com/a/branch", Commit, strings.Repeat("3", 40), "", strings.Repeat("3", 40)},
+		{"github.com/a/unpinned", Commit, strings.Repeat("4", 40), "", strings.Repeat("4", 40)},
+		{"github.com/a/indirect", Commit, strings.Repeat("5", 40), "", strings.Repeat("5", 40)},
+	}
+	for _, tt := range tests {
+		entry, ok := entries[tt.path]
+		if !ok {
+			t.Errorf("%s wasn't read", tt.path)
+			continue
+		}
+		if entry.GitType != tt.gitType || entry.CommitVersion != tt.version || entry.VersionRange != tt.versionRange || entry.LockedRevision != tt.locked {
+			t.Errorf("%s: type %v version %s range %q locked %s, want type %v version %s range %q locked %s", tt.path,
+				entry.GitType, entry.CommitVersion, entry.VersionRange, entry.LockedRevision, tt.gitType, tt.version, tt.versionRange, tt.locked)
+		}
+	}
+}
+
+func TestGlideUpdateFile(t *testing.T) {
+	depPath := writeGlideFiles(t)
+	p := NewGlideParser(filepath.Dir(depPath), depPath, utils.NewLogger(false))
+	list, contents, m, entries := p.ReadFile(filepath.Dir(depPath), depPath)
+	// the range keeps its constraint and moves in the lock, the exact version is rewritten
+	rangeEntry, tagEntry, plainEntry := entries["github.com/a/range"], entries["github.com/a/tag"], entries["github.com/a/plain"]
+	rangeEntry.CommitVersion, rangeEntry.GitType = "v0.8.1", Tag
+	rangeEntry.IsUpdated, rangeEntry.NewCommitVersion, rangeEntry.NewRevision = false, "v0.8.3", strings.Repeat("6", 40)
+	tagEntry.IsUpdated, tagEntry.NewCommitVersion, tagEntry.NewRevision = false, "v1.3.0", strings.Repeat("7", 40)
+	// the analysis found the plain version as tag v1.2.0
+	plainEntry.CommitVersion = "v1.2.0"
+	plainEntry.IsUpdated, plainEntry.NewCommitVersion, plainEntry.NewRevision = false, "v1.2.1", strings.Repeat("8", 40)
+	p.UpdateFile(list, contents, m, entries)
+
+	yaml := readFixture(t, filepath.Dir(depPath), "glide.yaml")
+	if !strings.Contains(yaml, "version: ^0.8.0") || !strings.Contains(yaml, "version: v1.3.0") || !strings.Contains(yaml, "version: 1.2.1\n") {
+		t.Errorf("glide.yaml is now:\n%s", yaml)
+	}
+	lock := readFixture(t, filepath.Dir(depPath), "glide.lock")
+	if !strings.Contains(lock, strings.Repeat("6", 40)) || !strings.Contains(lock, strings.Repeat("7", 40)) || !strings.Contains(lock, strings.Repeat("8", 40)) {
+		t.Errorf("glide.lock is now:\n%s", lock)
+	}
+}
@@ -0,0 +1,120 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// describeSuffix - the part `git describe` adds when a commit isn't exactly on a tag (v1.0.0-3-gabcdef1)
+var describeSuffix = regexp.MustCompile(`-[0-9]+-g[0-9a-f]+$`)
+
+// godeps - the Godeps/Godeps.json layout, as written by the godep tool
+type godeps struct {
+	ImportPath   string
+	GoVersion    string
+	GodepVersion string
+	Packages     []string `json:",omitempty"`
+	Deps         []godepDependency
+}
+
+type godepDependency struct {
+	ImportPath string
+	Comment    string `json:",omitempty"`
+	Rev        string
+}
+
+type GodepParser struct {
+	gitRoot string
+	depPath string
+	logger  *utils.Logger
+}
+
+func NewGodepParser(gitRoot, depPath string, logger *utils.Logger) *GodepParser {
+	return &GodepParser{gitRoot, depPath, logger}
+}
+
+func (p *GodepParser) GitRoot() string {
+	return p.gitRoot
+}
+
+func (p *GodepParser) DepPath() string {
+	return p.depPath
+}
+
+func (p *GodepParser) parse(contents string) *godeps {
+	g := &godeps{}
+	if err := json.Unmarshal([]byte(contents), g); err != nil {
+		p.logger.PanicWithMessage("failed to parse %s. error: %v", p.depPath, err)
+	}
+	return g
+}
+
+func (p *GodepParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[string]string, map[string]*Entry) {
+	entries := make([]*Entry, 0)
+	contents := utils.ReadFileContents(godepsPath, p.logger)
+	p.logger.LogDebug("got file contents %s", contents)
+	m := make(map[string]string)
+	me := make(map[string]*Entry)
+	g := p.parse(contents)
+	for _, d := range g.Deps {
+		root := RepoRoot(d.ImportPath)
+		if entry, ok := me[root]; ok {
+			// another package of a repository we already have
+			entry.SubPackages = append(entry.SubPackages, d.ImportPath)
+			if entry.LockedRevision != d.Rev {
+				p.logger.LogInfo("packages of %s are saved at different revisions (%s, %s); using %s", root, entry.LockedRevision, d.Rev, entry.LockedRevision)
+			}
+			continue
+		}
+		entry := NewEntry(root, d.Rev, "")
+		entry.LockedRevision = d.Rev
+		if d.Comment != "" && !describeSuffix.MatchString(d.Comment) {
+			// the saved revision is exactly a tag
+			entry.GitType = Tag
+			entry.CommitVersion = d.Comment
+		}
+		entry.SubPackages = []string{d.ImportPath}
+		m[root] = d.ImportPath
+		me[root] = entry
+		entries = append(entries, entry)
+	}
+	return entries, contents, m, me
+}
+
+func (p *GodepParser) UpdateFile(entries []*Entry, content string, contentMap map[string]string, entryMap map[string]*Entry) {
+	needUpdate := false
+	g := p.parse(content)
+	for i, d := range g.Deps {
+		entry, ok := entryMap[RepoRoot(d.ImportPath)]
+		if !ok || !ShouldUpdate(entry) || entry.NewRevision == "" {
+			continue
+		}
+		p.logger.LogInfo("updating package %s of entry %s", d.ImportPath, entry.Path)
+		g.Deps[i].Rev = entry.NewRevision
+		if entry.GitType == Tag {
+			g.Deps[i].Comment = entry.NewCommitVersion
+		} else {
+			// the old description no longer matches the revision
+			g.Deps[i].Comment = ""
+		}
+		needUpdate = true
+	}
+	if needUpdate {
+		// same layout godep itself writes
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "\t")
+		if err := encoder.Encode(g); err != nil {
+			p.logger.PanicWithMessage("failed to encode %s. error: %v", p.depPath, err)
+		}
+		content = buf.String()
+		p.logger.LogDebug("content is now:ֿ\n%s", content)
+		p.logger.LogInfo("Updating file")
+		utils.WriteFile(p.DepPath(), content, p.logger)
+	} else {
+		p.logger.LogInfo("File already updated")
+	}
+}
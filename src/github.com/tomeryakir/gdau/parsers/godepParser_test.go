@@ -0,0 +1,101 @@
+package parsers
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testGodeps = `{
+	"ImportPath": "example.com/app",
+	"GoVersion": "go1.9",
+	"GodepVersion": "v79",
+	"Deps": [
+		{
+			"ImportPath": "github.com/a/tagged",
+			"Comment": "v1.2.0",
+			"Rev": "1111111111111111111111111111111111111111"
+		},
+		{
+			"ImportPath": "github.com/a/tagged/sub",
+			"Comment": "v1.2.0",
+			"Rev": "1111111111111111111111111111111111111111"
+		},
+		{
+			"ImportPath": "github.com/a/described",
+			"Comment": "v1.0.0-3-gabcdef1",
+			"Rev": "2222222222222222222222222222222222222222"
+		},
+		{
+			"ImportPath": "golang.org/x/net/context",
+			"Rev": "3333333333333333333333333333333333333333"
+		}
+	]
+}
+`
+
+func TestGodepReadFile(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"Godeps/Godeps.json": testGodeps})
+	depPath := filepath.Join(dir, "Godeps", "Godeps.json")
+	entries, _, _, entryMap := NewGodepParser(dir, depPath, utils.NewLogger(false)).ReadFile(dir, depPath)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want one per repository", len(entries))
+	}
+	tests := []struct {
+		path        string
+		gitType     EntryType
+		version     string
+		subPackages []string
+	}{
+		{"github.com/a/tagged", Tag, "v1.2.0", []string{"github.com/a/tagged", "github.com/a/tagged/sub"}},
+		// a described commit isn't exactly on the tag
+		{"github.com/a/described", Commit, strings.Repeat("2", 40), []string{"github.com/a/described"}},
+		{"golang.org/x/net", Commit, strings.Repeat("3", 40), []string{"golang.org/x/net/context"}},
+	}
+	for _, tt := range tests {
+		entry := entryMap[tt.path]
+		if entry == nil {
+			t.Errorf("%s wasn't read", tt.path)
+			continue
+		}
+		if entry.GitType != tt.gitType || entry.CommitVersion != tt.version || !reflect.DeepEqual(entry.SubPackages, tt.subPackages) {
+			t.Errorf("%s: type %v version %s packages %v, want type %v version %s packages %v", tt.path,
+				entry.GitType, entry.CommitVersion, entry.SubPackages, tt.gitType, tt.version, tt.subPackages)
+		}
+	}
+}
+
+func TestGodepUpdateFile(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"Godeps/Godeps.json": testGodeps})
+	depPath := filepath.Join(dir, "Godeps", "Godeps.json")
+	p := NewGodepParser(dir, depPath, utils.NewLogger(false))
+	entries, contents, m, entryMap := p.ReadFile(dir, depPath)
+	tagged, described := entryMap["github.com/a/tagged"], entryMap["github.com/a/described"]
+	tagged.IsUpdated, tagged.NewCommitVersion, tagged.NewRevision = false, "v1.3.0", strings.Repeat("4", 40)
+	described.IsUpdated, described.NewCommitVersion, described.NewRevision = false, strings.Repeat("5", 40), strings.Repeat("5", 40)
+	p.UpdateFile(entries, contents, m, entryMap)
+
+	updated := readFixture(t, dir, "Godeps/Godeps.json")
+	if !strings.Contains(updated, "\n\t\"Deps\": [\n\t\t{\n") {
+		t.Errorf("the file isn't indented with tabs like godep writes it:\n%s", updated)
+	}
+	g := &godeps{}
+	if err := json.Unmarshal([]byte(updated), g); err != nil {
+		t.Fatal(err)
+	}
+	want := []godepDependency{
+		// every package of a repository moves
+		{"github.com/a/tagged", "v1.3.0", strings.Repeat("4", 40)},
+		{"github.com/a/tagged/sub", "v1.3.0", strings.Repeat("4", 40)},
+		// the old description no longer matches
+		{"github.com/a/described", "", strings.Repeat("5", 40)},
+		{"golang.org/x/net/context", "", strings.Repeat("3", 40)},
+	}
+	if !reflect.DeepEqual(g.Deps, want) || g.ImportPath != "example.com/app" || g.GodepVersion != "v79" {
+		t.Errorf("got %+v", g)
+	}
+}
@@ -0,0 +1,427 @@
+package parsers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tomeryakir/gdau/semver"
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const (
+	indirectComment     = "// indirect"
+	incompatibleSuffix  = "+incompatible"
+	pseudoVersionLayout = "20060102150405"
+)
+
+type GoModParser struct {
+	gitRoot string
+	depPath string
+	logger  *utils.Logger
+}
+
+func NewGoModParser(gitRoot, depPath string, logger *utils.Logger) *GoModParser {
+	return &GoModParser{gitRoot, depPath, logger}
+}
+
+func (p *GoModParser) GitRoot() string {
+	return p.gitRoot
+}
+
+func (p *GoModParser) DepPath() string {
+	return p.depPath
+}
+
+// goModRequire - a single requirement parsed out of a go.mod line
+type goModRequire struct {
+	path     string
+	version  string
+	indirect bool
+}
+
+// parseGoModRequire - parse a require line (without the "require" keyword); returns false if the line isn't a requirement
+func parseGoModRequire(line string) (goModRequire, bool) {
+	r := goModRequire{}
+	code := line
+	if i := strings.Index(line, "//"); i >= 0 {
+		code = line[:i]
+		r.indirect = strings.HasPrefix(strings.TrimSpace(line[i:]), indirectComment)
+	}
+	tokens := strings.Fields(code)
+	if len(tokens) != 2 {
+		return r, false
+	}
+	r.path = utils.ClearQuotes(tokens[0])
+	r.version = utils.ClearQuotes(tokens[1])
+	return r, true
+}
+
+// goModDirective - returns the directive keyword of a line and whether it opens a block
+func goModDirective(line string) (string, bool) {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return "", false
+	}
+	return tokens[0], len(tokens) > 1 && tokens[1] == "("
+}
+
+// goModLines - walk the go.mod lines, calling fn with the directive each line belongs to and the line without the keyword
+func goModLines(contents string, fn func(idx int, directive, body string)) {
+	lines := strings.Split(contents, "\n")
+	block := ""
+	for idx, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if block != "" {
+			if trimmed == ")" {
+				block = ""
+				continue
+			}
+			fn(idx, block, line)
+			continue
+		}
+		directive, opensBlock := goModDirective(trimmed)
+		if opensBlock {
+			block = directive
+			continue
+		}
+		fn(idx, directive, strings.TrimPrefix(trimmed, directive))
+	}
+}
+
+func (p *GoModParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[string]string, map[string]*Entry) {
+	entries := make([]*Entry, 0)
+	contents := utils.ReadFileContents(godepsPath, p.logger)
+	p.logger.LogDebug("got file contents %s", contents)
+	m := make(map[string]string)
+	me := make(map[string]*Entry)
+	lines := strings.Split(contents, "\n")
+	requires := make([]goModRequire, 0)
+	replaces := make([]goModReplace, 0)
+	excludes := make(map[string][]string)
+	goModLines(contents, func(idx int, directive, body string) {
+		switch directive {
+		case "require":
+			r, ok := parseGoModRequire(body)
+			if !ok {
+				p.logger.LogInfo("couldn't parse require line: %s", lines[idx])
+				return
+			}
+			m[r.path] = lines[idx]
+			requires = append(requires, r)
+		case "replace":
+			r, ok := parseGoModReplace(body)
+			if !ok {
+				p.logger.LogInfo("couldn't parse replace line: %s", lines[idx])
+				return
+			}
+			replaces = append(replaces, r)
+		case "exclude":
+			r, ok := parseGoModRequire(body)
+			if !ok {
+				p.logger.LogInfo("couldn't parse exclude line: %s", lines[idx])
+				return
+			}
+			excludes[r.path] = append(excludes[r.path], strings.TrimSuffix(r.version, incompatibleSuffix))
+		}
+	})
+	for _, r := range requires {
+		modPath, version := r.path, r.version
+		entry := NewEntry(r.path, "", "")
+		entry.IsIndirect = r.indirect
+		if rep, ok := findGoModReplace(replaces, r); ok {
+			entry.ReplacePath = rep.newPath
+			if rep.isLocal() {
+				p.logger.LogInfo("%s is replaced by local path %s, skipping", r.path, rep.newPath)
+				entry.CommitVersion = r.version
+				entry.IsSkipped = true
+				entry.Summary = fmt.Sprintf("replaced by local path %s", rep.newPath)
+				me[r.path] = entry
+				entries = append(entries, entry)
+				continue
+			}
+			modPath, version = rep.newPath, rep.newVersion
+		}
+		entry.CommitVersion = moduleVersionToGit(version)
+		if IsPseudoVersion(version) {
+			entry.GitType = Commit
+		} else {
+			entry.GitType = Tag
+		}
+		entry.ModuleMajor, entry.AllowsIncompatible = moduleMajor(modPath)
+		if repo := modulePathToRepo(modPath); repo != r.path {
+			entry.EffectivePath = repo
+		}
+		entry.ExcludedVersions = excludes[modPath]
+		me[r.path] = entry
+		entries = append(entries, entry)
+	}
+	return entries, contents, m, me
+}
+
+func (p *GoModParser) UpdateFile(entries []*Entry, content string, contentMap map[string]string, entryMap map[string]*Entry) {
+	needUpdate := false
+	lines := strings.Split(content, "\n")
+	goModLines(content, func(idx int, directive, body string) {
+		var modPath, oldPath, oldVersion string
+		switch directive {
+		case "require":
+			r, ok := parseGoModRequire(body)
+			if !ok {
+				return
+			}
+			modPath, oldPath, oldVersion = r.path, r.path, r.version
+		case "replace":
+			r, ok := parseGoModReplace(body)
+			if !ok || r.isLocal() {
+				return
+			}
+			modPath, oldPath, oldVersion = r.oldPath, r.newPath, r.newVersion
+		default:
+			return
+		}
+		entry, ok := entryMap[modPath]
+		if !ok || !ShouldUpdate(entry) {
+			return
+		}
+		// replaced modules are updated on their replace line, the require line is left alone
+		if directive == "require" && entry.ReplacePath != "" || directive == "replace" && entry.ReplacePath != oldPath {
+			return
+		}
+		newVersion, err := gitToModuleVersion(oldVersion, entry)
+		if err != nil {
+			p.logger.LogInfo("not updating %s: %v", modPath, err)
+			return
+		}
+		p.logger.LogInfo("updating entry %v", entry)
+		lines[idx] = replaceVersionToken(lines[idx], oldPath, oldVersion, newVersion)
+		needUpdate = true
+	})
+	if needUpdate {
+		content = strings.Join(lines, "\n")
+		p.logger.LogDebug("content is now:ֿ\n%s", content)
+		p.logger.LogInfo("Updating file")
+		utils.WriteFile(p.DepPath(), content, p.logger)
+	} else {
+		p.logger.LogInfo("File already updated")
+	}
+}
+
+// goModReplace - a replace directive; oldVersion is empty when all versions are replaced, newVersion is empty for local paths
+type goModReplace struct {
+	oldPath    string
+	oldVersion string
+	newPath    string
+	newVersion string
+}
+
+func (r goModReplace) isLocal() bool {
+	return r.newVersion == "" || strings.HasPrefix(r.newPath, ".") || strings.HasPrefix(r.newPath, "/")
+}
+
+// parseGoModReplace - parse a replace line (without the "replace" keyword)
+func parseGoModReplace(line string) (goModReplace, bool) {
+	r := goModReplace{}
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	sides := strings.Split(line, "=>")
+	if len(sides) != 2 {
+		return r, false
+	}
+	left := strings.Fields(sides[0])
+	right := strings.Fields(sides[1])
+	if len(left) < 1 || len(left) > 2 || len(right) < 1 || len(right) > 2 {
+		return r, false
+	}
+	r.oldPath = utils.ClearQuotes(left[0])
+	if len(left) == 2 {
+		r.oldVersion = utils.ClearQuotes(left[1])
+	}
+	r.newPath = utils.ClearQuotes(right[0])
+	if len(right) == 2 {
+		r.newVersion = utils.ClearQuotes(right[1])
+	}
+	return r, true
+}
+
+// findGoModReplace - the replacement for a requirement; a version-specific replace wins over a path-wide one
+func findGoModReplace(replaces []goModReplace, req goModRequire) (goModReplace, bool) {
+	var found goModReplace
+	ok := false
+	for _, r := range replaces {
+		if r.oldPath != req.path {
+			continue
+		}
+		if r.oldVersion == req.version {
+			return r, true
+		}
+		if r.oldVersion == "" {
+			found, ok = r, true
+		}
+	}
+	return found, ok
+}
+
+// modulePathToRepo - strip the major version suffix (/v2, /v3...) that lives inside the repository
+func modulePathToRepo(modPath string) string {
+	if strings.HasPrefix(modPath, "gopkg.in/") {
+		return modPath
+	}
+	i := strings.LastIndex(modPath, "/v")
+	if i < 0 || !isDigits(modPath[i+2:]) {
+		return modPath
+	}
+	return modPath[:i]
+}
+
+// moduleMajor - the major version a module path implies (1 for v0 and v1), and whether v2 and up can be required on it as +incompatible versions,
+// which only paths without a major version suffix can
+func moduleMajor(modPath string) (int, bool) {
+	suffix := ""
+	if strings.HasPrefix(modPath, "gopkg.in/") {
+		// gopkg.in/yaml.v2
+		if i := strings.LastIndex(modPath, ".v"); i >= 0 {
+			suffix = modPath[i+2:]
+		}
+	} else if i := strings.LastIndex(modPath, "/v"); i >= 0 {
+		suffix = modPath[i+2:]
+	}
+	if !isDigits(suffix) {
+		return 1, !strings.HasPrefix(modPath, "gopkg.in/")
+	}
+	major, _ := strconv.Atoi(suffix)
+	if major < 1 {
+		major = 1
+	}
+	return major, false
+}
+
+// replaceVersionToken - replace the version that follows the module path in a line, leaving spacing and comments alone
+func replaceVersionToken(line, modPath, oldVersion, newVersion string) string {
+	pathIdx := strings.Index(line, modPath)
+	if pathIdx < 0 {
+		return line
+	}
+	rest := line[pathIdx+len(modPath):]
+	verIdx := strings.Index(rest, oldVersion)
+	if verIdx < 0 {
+		return line
+	}
+	return line[:pathIdx+len(modPath)] + rest[:verIdx] + newVersion + rest[verIdx+len(oldVersion):]
+}
+
+// IsPseudoVersion - whether a module version is a pseudo-version (vX.Y.Z-yyyymmddhhmmss-abcdefabcdef)
+func IsPseudoVersion(v string) bool {
+	v = strings.TrimSuffix(v, incompatibleSuffix)
+	tokens := strings.Split(v, "-")
+	if len(tokens) < 3 {
+		return false
+	}
+	hash := tokens[len(tokens)-1]
+	stamp := tokens[len(tokens)-2]
+	if i := strings.LastIndex(stamp, "."); i >= 0 {
+		stamp = stamp[i+1:]
+	}
+	return len(hash) == 12 && isHexString(hash) && len(stamp) == len(pseudoVersionLayout) && isDigits(stamp)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// moduleVersionToGit - the git ref matching a module version; the commit hash for pseudo-versions, the tag otherwise
+func moduleVersionToGit(v string) string {
+	if IsPseudoVersion(v) {
+		tokens := strings.Split(strings.TrimSuffix(v, incompatibleSuffix), "-")
+		return tokens[len(tokens)-1]
+	}
+	return strings.TrimSuffix(v, incompatibleSuffix)
+}
+
+// gitToModuleVersion - turn the analyzed new git ref of an entry back into a go.mod version
+func gitToModuleVersion(oldVersion string, entry *Entry) (string, error) {
+	if entry.GitType != Commit {
+		v := entry.NewCommitVersion
+		if strings.HasSuffix(v, incompatibleSuffix) {
+			return v, nil
+		}
+		version, err := semver.Parse(v)
+		if err != nil {
+			return "", fmt.Errorf("%s isn't a module version", v)
+		}
+		suffix, err := moduleVersionSuffix(v, version, entry)
+		if err != nil {
+			return "", err
+		}
+		return v + suffix, nil
+	}
+	if entry.NewCommitTime.IsZero() {
+		return "", fmt.Errorf("commit time of %s is unknown", entry.NewCommitVersion)
+	}
+	if len(entry.NewCommitVersion) < 12 {
+		return "", fmt.Errorf("commit %s is too short for a pseudo-version", entry.NewCommitVersion)
+	}
+	base, suffix, err := pseudoVersionBase(oldVersion, entry)
+	if err != nil {
+		return "", err
+	}
+	stamp := entry.NewCommitTime.UTC().Format(pseudoVersionLayout)
+	return fmt.Sprintf("%s%s-%s%s", base, stamp, entry.NewCommitVersion[:12], suffix), nil
+}
+
+// moduleVersionSuffix - what a version takes to be required on the module path of an entry: nothing for the major version the path implies,
+// +incompatible for v2 and up on a path without a major version suffix; an error for the other major versions
+func moduleVersionSuffix(v string, version *semver.Version, entry *Entry) (string, error) {
+	// v0 and v1 share the path without a suffix
+	major := version.Major()
+	if major < 1 {
+		major = 1
+	}
+	switch {
+	case major == entry.ModuleMajor:
+		return "", nil
+	case major > 1 && entry.AllowsIncompatible:
+		return incompatibleSuffix, nil
+	}
+	return "", fmt.Errorf("%s is another major version than the module path of %s allows", v, entry.Path)
+}
+
+// pseudoVersionBase - the part of a pseudo-version before its time, and its +incompatible suffix, for a commit that descends from the old version.
+// Like the go command derives it from the tag below the commit: vX.Y.(Z+1)-0. after a release, vX.Y.Z-pre.0. after a pre-release,
+// and the base of the old pseudo-version, which already names that tag
+func pseudoVersionBase(oldVersion string, entry *Entry) (string, string, error) {
+	old := strings.TrimSuffix(oldVersion, incompatibleSuffix)
+	suffix := ""
+	if old != oldVersion {
+		suffix = incompatibleSuffix
+	}
+	if IsPseudoVersion(old) {
+		return old[:len(old)-len(pseudoVersionLayout)-len("-")-12], suffix, nil
+	}
+	version, err := semver.Parse(old)
+	if err != nil {
+		// no tag to start from
+		major := entry.ModuleMajor
+		if major < 2 {
+			major = 0
+		}
+		return fmt.Sprintf("v%d.0.0-", major), "", nil
+	}
+	suffix, err = moduleVersionSuffix(old, version, entry)
+	if err != nil {
+		return "", "", err
+	}
+	if version.IsPreRelease() {
+		return fmt.Sprintf("v%d.%d.%d-%s.0.", version.Major(), version.Minor(), version.Patch(), strings.Join(version.PreRelease, ".")), suffix, nil
+	}
+	return fmt.Sprintf("v%d.%d.%d-0.", version.Major(), version.Minor(), version.Patch()+1), suffix, nil
+}
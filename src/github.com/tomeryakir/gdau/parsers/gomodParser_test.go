@@ -0,0 +1,288 @@
+package parsers
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.13
+
+require github.com/a/single v1.0.0
+
+require (
+	github.com/a/tag v1.2.0 // a comment
+	github.com/a/indirect v0.3.0 // indirect
+	github.com/a/incompatible v3.1.0+incompatible
+	github.com/a/pseudo v0.0.0-20200101000000-abcdefabcdef
+	github.com/a/based v1.2.4-0.20200101000000-abcdefabcdef
+	github.com/a/lib/v2 v2.1.0
+	gopkg.in/yaml.v2 v2.2.8
+)
+`
+
+func readGoMod(t *testing.T, contents string) (*GoModParser, string, []*Entry, string, map[string]string, map[string]*Entry) {
+	t.Helper()
+	dir := writeFixture(t, map[string]string{"go.mod": contents})
+	depPath := filepath.Join(dir, "go.mod")
+	p := NewGoModParser(dir, depPath, utils.NewLogger(false))
+	entries, content, m, entryMap := p.ReadFile(dir, depPath)
+	return p, dir, entries, content, m, entryMap
+}
+
+func TestGoModReadFile(t *testing.T) {
+	_, _, entries, _, _, entryMap := readGoMod(t, testGoMod)
+	if len(entries) != 8 {
+		t.Fatalf("got %d entries, want 8", len(entries))
+	}
+	tests := []struct {
+		path         string
+		gitType      EntryType
+		version      string
+		repo         string
+		major        int
+		incompatible bool
+		indirect     bool
+	}{
+		{path: "github.com/a/single", gitType: Tag, version: "v1.0.0", repo: "github.com/a/single", major: 1, incompatible: true},
+		{path: "github.com/a/tag", gitType: Tag, version: "v1.2.0", repo: "github.com/a/tag", major: 1, incompatible: true},
+		{path: "github.com/a/indirect", gitType: Tag, version: "v0.3.0", repo: "github.com/a/indirect", major: 1, incompatible: true, indirect: true},
+		{path: "github.com/a/incompatible", gitType: Tag, version: "v3.1.0", repo: "github.com/a/incompatible", major: 1, incompatible: true},
+		{path: "github.com/a/pseudo", gitType: Commit, version: "abcdefabcdef", repo: "github.com/a/pseudo", major: 1, incompatible: true},
+		{path: "github.com/a/based", gitType: Commit, version: "abcdefabcdef", repo: "github.com/a/based", major: 1, incompatible: true},
+		// the major version suffix lives in the repository
+		{path: "github.com/a/lib/v2", gitType: Tag, version: "v2.1.0", repo: "github.com/a/lib", major: 2},
+		{path: "gopkg.in/yaml.v2", gitType: Tag, version: "v2.2.8", repo: "gopkg.in/yaml.v2", major: 2},
+	}
+	for _, tt := range tests {
+		entry := entryMap[tt.path]
+		if entry == nil {
+			t.Errorf("%s wasn't read", tt.path)
+			continue
+		}
+		if entry.GitType != tt.gitType || entry.CommitVersion != tt.version || entry.RepoPath() != tt.repo || entry.ModuleMajor != tt.major ||
+			entry.AllowsIncompatible != tt.incompatible || entry.IsIndirect != tt.indirect {
+			t.Errorf("%s: got %+v", tt.path, entry)
+		}
+	}
+}
+
+func TestGoModUpdateFile(t *testing.T) {
+	p, dir, entries, content, m, entryMap := readGoMod(t, testGoMod)
+	at := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	update := func(path, version string) {
+		entry := entryMap[path]
+		entry.IsUpdated = false
+		entry.NewCommitVersion = version
+		entry.NewRevision = version
+		entry.NewCommitTime = at
+	}
+	update("github.com/a/single", "v1.1.0")
+	update("github.com/a/tag", "v1.3.0")
+	update("github.com/a/indirect", "v0.4.0")
+	update("github.com/a/incompatible", "v3.2.0")
+	update("github.com/a/pseudo", "0123456789ab0123456789ab0123456789ab0123")
+	update("github.com/a/based", "0123456789ab0123456789ab0123456789ab0123")
+	p.UpdateFile(entries, content, m, entryMap)
+
+	want := `module example.com/app
+
+go 1.13
+
+require github.com/a/single v1.1.0
+
+require (
+	github.com/a/tag v1.3.0 // a comment
+	github.com/a/indirect v0.4.0 // indirect
+	github.com/a/incompatible v3.2.0+incompatible
+	github.com/a/pseudo v0.0.0-20210203040506-0123456789ab
+	github.com/a/based v1.2.4-0.20210203040506-0123456789ab
+	github.com/a/lib/v2 v2.1.0
+	gopkg.in/yaml.v2 v2.2.8
+)
+`
+	if got := readFixture(t, dir, "go.mod"); got != want {
+		t.Errorf("go.mod is now:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGoModUpdateFileBranchRule(t *testing.T) {
+	p, dir, entries, content, m, entryMap := readGoMod(t, "module example.com/app\n\nrequire (\n\tgithub.com/a/lib v1.4.2\n\tgithub.com/b/lib/v2 v2.1.0\n)\n")
+	for _, entry := range entries {
+		// what the analysis of a branch rule makes of a release
+		entry.IsUpdated, entry.GitType = false, Commit
+		entry.NewCommitVersion = "0123456789ab0123456789ab0123456789ab0123"
+		entry.NewCommitTime = time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	}
+	p.UpdateFile(entries, content, m, entryMap)
+	want := "module example.com/app\n\nrequire (\n\tgithub.com/a/lib v1.4.3-0.20210203040506-0123456789ab\n\tgithub.com/b/lib/v2 v2.1.1-0.20210203040506-0123456789ab\n)\n"
+	if got := readFixture(t, dir, "go.mod"); got != want {
+		t.Errorf("go.mod is now:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGitToModuleVersion(t *testing.T) {
+	at := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	tests := []struct {
+		name         string
+		old          string
+		gitType      EntryType
+		newVersion   string
+		major        int
+		incompatible bool
+		want         string
+		err          string
+	}{
+		{name: "same major", old: "v1.0.0", gitType: Tag, newVersion: "v1.5.0", major: 1, incompatible: true, want: "v1.5.0"},
+		{name: "v0 on a path without a suffix", old: "v0.1.0", gitType: Tag, newVersion: "v0.2.0", major: 1, incompatible: true, want: "v0.2.0"},
+		{name: "incompatible", old: "v1.0.0", gitType: Tag, newVersion: "v2.0.0", major: 1, incompatible: true, want: "v2.0.0+incompatible"},
+		{name: "already incompatible", old: "v2.0.0+incompatible", gitType: Tag, newVersion: "v3.0.0+incompatible", major: 1, incompatible: true, want: "v3.0.0+incompatible"},
+		{name: "suffixed path", old: "v2.0.0", gitType: Tag, newVersion: "v2.3.0", major: 2, want: "v2.3.0"},
+		{name: "another major on a suffixed path", old: "v2.0.0", gitType: Tag, newVersion: "v3.0.0", major: 2, err: "another major version"},
+		{name: "gopkg.in", old: "v2.2.8", gitType: Tag, newVersion: "v3.0.0", major: 2, err: "another major version"},
+		{name: "not a version", old: "v1.0.0", gitType: Tag, newVersion: "latest", major: 1, err: "isn't a module version"},
+		{name: "pseudo-version", old: "v0.0.0-20200101000000-abcdefabcdef", gitType: Commit, newVersion: "0123456789ab0123", major: 1,
+			want: "v0.0.0-20210203040506-0123456789ab"},
+		{name: "pseudo-version after a release", old: "v1.2.4-0.20200101000000-abcdefabcdef", gitType: Commit, newVersion: "0123456789ab0123", major: 1,
+			want: "v1.2.4-0.20210203040506-0123456789ab"},
+		{name: "pseudo-version after a pre-release", old: "v1.2.4-rc.1.0.20200101000000-abcdefabcdef", gitType: Commit, newVersion: "0123456789ab0123", major: 1,
+			want: "v1.2.4-rc.1.0.20210203040506-0123456789ab"},
+		{name: "incompatible pseudo-version", old: "v2.0.1-0.20200101000000-abcdefabcdef+incompatible", gitType: Commit, newVersion: "0123456789ab0123", major: 1, incompatible: true,
+			want: "v2.0.1-0.20210203040506-0123456789ab+incompatible"},
+		// a release that moves to a commit of a branch (a branch rule) sorts above the release, on the major version of its path
+		{name: "commit after a release", old: "v1.4.2", gitType: Commit, newVersion: "0123456789ab0123", major: 1, incompatible: true,
+			want: "v1.4.3-0.20210203040506-0123456789ab"},
+		{name: "commit after a release on a suffixed path", old: "v2.1.0", gitType: Commit, newVersion: "0123456789ab0123", major: 2,
+			want: "v2.1.1-0.20210203040506-0123456789ab"},
+		{name: "commit after an incompatible release", old: "v2.1.0+incompatible", gitType: Commit, newVersion: "0123456789ab0123", major: 1, incompatible: true,
+			want: "v2.1.1-0.20210203040506-0123456789ab+incompatible"},
+		{name: "commit after a pre-release", old: "v1.0.0-rc.1", gitType: Commit, newVersion: "0123456789ab0123", major: 1, incompatible: true,
+			want: "v1.0.0-rc.1.0.20210203040506-0123456789ab"},
+		{name: "commit after a release of another major", old: "v3.0.0", gitType: Commit, newVersion: "0123456789ab0123", major: 2, err: "another major version"},
+		{name: "short commit", old: "v0.0.0-20200101000000-abcdefabcdef", gitType: Commit, newVersion: "0123", major: 1, err: "too short"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &Entry{Path: "github.com/a/lib", GitType: tt.gitType, NewCommitVersion: tt.newVersion, NewCommitTime: at,
+				ModuleMajor: tt.major, AllowsIncompatible: tt.incompatible}
+			got, err := gitToModuleVersion(tt.old, entry)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("got %s, %v, want an error with %q", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+
+	entry := &Entry{Path: "github.com/a/lib", GitType: Commit, NewCommitVersion: "0123456789ab0123", ModuleMajor: 1}
+	if _, err := gitToModuleVersion("v0.0.0-20200101000000-abcdefabcdef", entry); err == nil {
+		t.Errorf("a commit without a time made a pseudo-version")
+	}
+}
+
+func TestIsPseudoVersion(t *testing.T) {
+	tests := map[string]bool{
+		"v0.0.0-20200101000000-abcdefabcdef":                true,
+		"v1.2.4-0.20200101000000-abcdefabcdef":              true,
+		"v1.2.4-rc.1.0.20200101000000-abcdefabcdef":         true,
+		"v2.0.1-0.20200101000000-abcdefabcdef+incompatible": true,
+		"v1.2.0":                       false,
+		"v1.2.0-rc.1":                  false,
+		"v0.0.0-2020-abcdefabcdef":     false,
+		"v0.0.0-20200101000000-abcdef": false,
+	}
+	for v, want := range tests {
+		if got := IsPseudoVersion(v); got != want {
+			t.Errorf("IsPseudoVersion(%s) = %v, want %v", v, got, want)
+		}
+	}
+}
+
+const testGoModReplace = `module example.com/app
+
+require (
+	github.com/a/forked v1.0.0
+	github.com/a/pinned v1.1.0
+	github.com/a/local v1.0.0
+	github.com/a/excluded/v2 v2.0.0
+)
+
+replace github.com/a/forked => github.com/fork/forked v1.0.1
+
+replace (
+	github.com/a/pinned v1.0.0 => github.com/fork/pinned v0.9.0
+	github.com/a/pinned v1.1.0 => github.com/fork/pinned/v3 v3.1.0
+	github.com/a/local => ../local
+)
+
+exclude github.com/a/excluded/v2 v2.1.0
+exclude (
+	github.com/a/excluded/v2 v2.2.0
+	github.com/a/forked v1.2.0
+)
+`
+
+func TestGoModReadFileReplaceAndExclude(t *testing.T) {
+	_, _, _, _, _, entryMap := readGoMod(t, testGoModReplace)
+	tests := []struct {
+		path     string
+		replace  string
+		version  string
+		repo     string
+		major    int
+		skipped  bool
+		excluded []string
+	}{
+		// the replacement is fetched and analyzed at its own version; excludes name the required module, not the replacement
+		{path: "github.com/a/forked", replace: "github.com/fork/forked", version: "v1.0.1", repo: "github.com/fork/forked", major: 1},
+		// a version-specific replace wins over the others
+		{path: "github.com/a/pinned", replace: "github.com/fork/pinned/v3", version: "v3.1.0", repo: "github.com/fork/pinned", major: 3},
+		{path: "github.com/a/local", replace: "../local", version: "v1.0.0", repo: "github.com/a/local", skipped: true},
+		{path: "github.com/a/excluded/v2", version: "v2.0.0", repo: "github.com/a/excluded", major: 2, excluded: []string{"v2.1.0", "v2.2.0"}},
+	}
+	for _, tt := range tests {
+		entry := entryMap[tt.path]
+		if entry == nil {
+			t.Errorf("%s wasn't read", tt.path)
+			continue
+		}
+		if entry.ReplacePath != tt.replace || entry.CommitVersion != tt.version || entry.RepoPath() != tt.repo || entry.ModuleMajor != tt.major ||
+			entry.IsSkipped != tt.skipped || !reflect.DeepEqual(entry.ExcludedVersions, tt.excluded) {
+			t.Errorf("%s: got %+v", tt.path, entry)
+		}
+	}
+}
+
+func TestGoModUpdateFileReplace(t *testing.T) {
+	p, dir, entries, content, m, entryMap := readGoMod(t, testGoModReplace)
+	for path, version := range map[string]string{"github.com/a/forked": "v1.1.0", "github.com/a/pinned": "v3.2.0", "github.com/a/excluded/v2": "v2.3.0"} {
+		entry := entryMap[path]
+		entry.IsUpdated, entry.NewCommitVersion = false, version
+	}
+	p.UpdateFile(entries, content, m, entryMap)
+	got := readFixture(t, dir, "go.mod")
+	// replaced modules move on their replace line, and only on the one that applies
+	for _, line := range []string{
+		"\tgithub.com/a/forked v1.0.0\n",
+		"\tgithub.com/a/pinned v1.1.0\n",
+		"\tgithub.com/a/excluded/v2 v2.3.0\n",
+		"replace github.com/a/forked => github.com/fork/forked v1.1.0\n",
+		"\tgithub.com/a/pinned v1.0.0 => github.com/fork/pinned v0.9.0\n",
+		"\tgithub.com/a/pinned v1.1.0 => github.com/fork/pinned/v3 v3.2.0\n",
+		"\tgithub.com/a/local => ../local\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("go.mod doesn't have %q:\n%s", line, got)
+		}
+	}
+}
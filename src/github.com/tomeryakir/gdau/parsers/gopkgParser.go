@@ -1,11 +1,21 @@
 package parsers
 
 import (
+	"path"
 	"strings"
 
+	"github.com/tomeryakir/gdau/semver"
+	"github.com/tomeryakir/gdau/toml"
 	"github.com/tomeryakir/gdau/utils"
 )
 
+const (
+	gopkgLockFile = "Gopkg.lock"
+)
+
+// constraint keys of a Gopkg.toml project, by precedence
+var gopkgConstraintKeys = []string{"revision", "version", "branch"}
+
 type GopkgParser struct {
 	gitRoot string
 	depPath string
@@ -24,94 +34,213 @@ func (p *GopkgParser) DepPath() string {
 	return p.depPath
 }
 
+// LockPath - the Gopkg.lock that sits next to the Gopkg.toml
+func (p *GopkgParser) LockPath() string {
+	return path.Join(path.Dir(p.depPath), gopkgLockFile)
+}
+
+func (p *GopkgParser) parseToml(filePath, contents string) *toml.Document {
+	doc, err := toml.Parse(contents)
+	if err != nil {
+		p.logger.PanicWithMessage("failed to parse %s. error: %v", filePath, err)
+	}
+	return doc
+}
+
+// readLock - the parsed Gopkg.lock, or nil if there's none
+func (p *GopkgParser) readLock() *toml.Document {
+	if !utils.DirExists(p.LockPath()) {
+		p.logger.LogInfo("%s wasn't found, only %s will be analyzed", p.LockPath(), p.depPath)
+		return nil
+	}
+	return p.parseToml(p.LockPath(), utils.ReadFileContents(p.LockPath(), p.logger))
+}
+
+// gopkgRules - the [[constraint]] and [[override]] projects of a Gopkg.toml, by name; an override supersedes a constraint
+func gopkgRules(doc *toml.Document) ([]string, map[string]*toml.Table, map[string]bool) {
+	names := make([]string, 0)
+	rules := make(map[string]*toml.Table)
+	overrides := make(map[string]bool)
+	for _, section := range []string{"constraint", "override"} {
+		for _, t := range doc.ArrayTables(section) {
+			name, ok := t.GetString("name")
+			if !ok {
+				continue
+			}
+			if _, ok := rules[name]; !ok {
+				names = append(names, name)
+			}
+			rules[name] = t
+			overrides[name] = section == "override"
+		}
+	}
+	return names, rules, overrides
+}
+
+// gopkgConstraint - the constraint key and value of a project table
+func gopkgConstraint(t *toml.Table) (string, string) {
+	for _, key := range gopkgConstraintKeys {
+		if v, ok := t.GetString(key); ok {
+			return key, v
+		}
+	}
+	return "", ""
+}
+
 func (p *GopkgParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[string]string, map[string]*Entry) {
 	entries := make([]*Entry, 0)
 	contents := utils.ReadFileContents(godepsPath, p.logger)
 	p.logger.LogDebug("got file contents %s", contents)
 	m := make(map[string]string)
 	me := make(map[string]*Entry)
-	lines := strings.Split(contents, "\n")
-	var currentEntry = &Entry{
-		IsUpdated: true,
-	}
-	for _, line := range lines {
-		if strings.HasPrefix(line, "#") {
-			continue
+	doc := p.parseToml(godepsPath, contents)
+
+	names, rules, overrides := gopkgRules(doc)
+	for _, name := range names {
+		t := rules[name]
+		entry := &Entry{Path: name, IsUpdated: true, IsOverride: overrides[name]}
+		source, _ := t.GetString("source")
+		entry.setRemote(source)
+		entry.ConstraintKind, entry.Constraint = gopkgConstraint(t)
+		// without a lock, the constraint is the best guess of the version in use
+		entry.CommitVersion = entry.Constraint
+		switch entry.ConstraintKind {
+		case "revision":
+			entry.GitType = Commit
+		case "branch":
+			entry.GitType = Branch
+			entry.Branch = entry.Constraint
+		default:
+			entry.GitType = Tag
+			if !isRange(entry.Constraint) {
+				entry.CommitVersion = exactVersion(entry.Constraint)
+			}
 		}
-		tokens := strings.Fields(line)
-		if len(tokens) < 3 {
+		me[name] = entry
+		entries = append(entries, entry)
+	}
+
+	lock := p.readLock()
+	if lock == nil {
+		skipUnlockedRanges(entries, gopkgLockFile)
+		return entries, contents, m, me
+	}
+	for _, project := range lock.ArrayTables("projects") {
+		name, ok := project.GetString("name")
+		if !ok {
 			continue
 		}
-		if tokens[0] == "name" {
-			if currentEntry.Path != "" {
-				entries = append(entries, currentEntry)
-			}
-			currentEntry = &Entry{IsUpdated: true}
-			currentEntry.Path = utils.ClearQuotes(tokens[2])
-			me[utils.ClearQuotes(tokens[2])] = currentEntry
-		}
-		if tokens[0] == "source" {
-			if strings.HasPrefix(utils.ClearQuotes(tokens[2]), "git@") {
-				p.logger.LogInfo("packages with @ in their paths aren't supported (yet). line: %s", line)
-				currentEntry.IsSkipped = true
-				currentEntry.Summary = "packages with @ in their paths aren't supported (yet)"
-			} else {
-				currentEntry.GitRemote = utils.ClearQuotes(tokens[2])
-			}
+		entry, ok := me[name]
+		if !ok {
+			// not constrained in Gopkg.toml - a transitive dependency
+			entry = &Entry{Path: name, IsUpdated: true, IsIndirect: true}
+			source, _ := project.GetString("source")
+			entry.setRemote(source)
+			me[name] = entry
+			entries = append(entries, entry)
 		}
-		if tokens[0] == "revision" {
-			currentEntry.GitType = Commit
-			currentEntry.CommitVersion = utils.ClearQuotes(tokens[2])
+		entry.LockedRevision, _ = project.GetString("revision")
+		if version, ok := project.GetString("version"); ok {
+			entry.GitType = Tag
+			entry.CommitVersion = version
+		} else {
+			entry.GitType = Commit
+			entry.CommitVersion = entry.LockedRevision
 		}
-		if tokens[0] == "version" {
-			currentEntry.GitType = BranchVersion
-			currentEntry.CommitVersion = utils.ClearQuotes(tokens[2])
+		if branch, ok := project.GetString("branch"); ok {
+			// the locked revision is compared against the head of the branch
+			entry.Branch = branch
 		}
 	}
-	me[currentEntry.Path] = currentEntry
-	entries = append(entries, currentEntry)
+	skipUnlockedRanges(entries, gopkgLockFile)
 	return entries, contents, m, me
 }
 
+// constraintAllows - whether the constraint in Gopkg.toml already accepts the new version of an entry
+func constraintAllows(entry *Entry) bool {
+	switch entry.ConstraintKind {
+	case "":
+		return true
+	case "branch":
+		return entry.GitType == Commit
+	case "revision":
+		return entry.Constraint == entry.NewRevision
+	}
+	if entry.GitType == Commit {
+		return false
+	}
+	ok, err := semver.Satisfies(entry.Constraint, entry.NewCommitVersion)
+	return err == nil && ok
+}
+
 func (p *GopkgParser) UpdateFile(entries []*Entry, content string, contentMap map[string]string, entryMap map[string]*Entry) {
-	needUpdate := false
-	shouldUpdateEntry := false
-	newContent := ""
-	var currentEntry *Entry
-	lines := strings.Split(content, "\n")
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "#") {
-			newContent += line + "\n"
+	p.updateLock(entryMap)
+	doc := p.parseToml(p.DepPath(), content)
+	names, rules, _ := gopkgRules(doc)
+	for _, name := range names {
+		t := rules[name]
+		entry, ok := entryMap[name]
+		if !ok || !ShouldUpdate(entry) {
 			continue
 		}
-		tokens := strings.Fields(line)
-		if len(tokens) < 3 {
-			newContent += line + "\n"
+		if entry.LockedRevision != "" && constraintAllows(entry) {
 			continue
 		}
-		if tokens[0] == "name" {
-			if entry, ok := entryMap[utils.ClearQuotes(tokens[2])]; ok {
-				if !entry.IsUpdated && !entry.IsProblem && !entry.IsSkipped {
-					shouldUpdateEntry = true
-					currentEntry = entry
-					needUpdate = true
-				}
-			}
-			newContent += line + "\n"
-		} else if (tokens[0] == "version" || tokens[0] == "revision") && shouldUpdateEntry {
-			newLine := strings.Replace(line, currentEntry.CommitVersion, currentEntry.NewCommitVersion, -1)
-			newContent += newLine + "\n"
-			shouldUpdateEntry = false
-		} else {
-			newContent += line + "\n"
+		key, _ := gopkgConstraint(t)
+		if key != "version" && key != "revision" {
+			continue
+		}
+		newVersion := entry.NewCommitVersion
+		if key == "revision" {
+			newVersion = entry.NewRevision
 		}
+		p.logger.LogInfo("updating constraint of %s to %s", name, newVersion)
+		v := t.Values[key]
+		doc.SetString(v, bumpConstraint(v.Str, newVersion))
 	}
-	if needUpdate {
-		p.logger.LogDebug("content is now:ֿ\n%s", newContent)
+	if doc.IsModified() {
+		p.logger.LogDebug("content is now:ֿ\n%s", doc.String())
 		p.logger.LogInfo("Updating file")
-		utils.WriteFile(p.DepPath(), newContent, p.logger)
+		utils.WriteFile(p.DepPath(), doc.String(), p.logger)
 	} else {
 		p.logger.LogInfo("File already updated")
 	}
 }
+
+// bumpConstraint - move a constraint to a new version, keeping its range operator and v-prefix style
+func bumpConstraint(constraint, newVersion string) string {
+	if strings.ContainsAny(strings.TrimSpace(constraint), ", |") {
+		// compound ranges are replaced by the new version, which dep reads as a caret range
+		return newVersion
+	}
+	bound := strings.TrimLeft(constraint, "^~=<>!")
+	if !strings.HasPrefix(bound, "v") {
+		newVersion = strings.TrimPrefix(newVersion, "v")
+	}
+	return constraint[:len(constraint)-len(bound)] + newVersion
+}
+
+// updateLock - point the outdated projects of Gopkg.lock at their new revision (and version)
+func (p *GopkgParser) updateLock(entryMap map[string]*Entry) {
+	lock := p.readLock()
+	if lock == nil {
+		return
+	}
+	for _, project := range lock.ArrayTables("projects") {
+		name, _ := project.GetString("name")
+		entry, ok := entryMap[name]
+		if !ok || !ShouldUpdate(entry) || entry.NewRevision == "" {
+			continue
+		}
+		if v, ok := project.Values["revision"]; ok {
+			lock.SetString(v, entry.NewRevision)
+		}
+		if v, ok := project.Values["version"]; ok && entry.GitType == Tag {
+			lock.SetString(v, entry.NewCommitVersion)
+		}
+	}
+	if lock.IsModified() {
+		p.logger.LogInfo("Updating lock file; run `dep ensure` to refresh the digests")
+		utils.WriteFile(p.LockPath(), lock.String(), p.logger)
+	}
+}
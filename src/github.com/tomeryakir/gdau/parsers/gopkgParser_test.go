@@ -0,0 +1,158 @@
+package parsers
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testGopkgToml = `[[constraint]]
+  name = "github.com/a/locked"
+  version = "^1.0.0"
+
+[[constraint]]
+  name = "github.com/a/range"
+  version = ">= 1.0.0, < 2.0.0"
+
+[[constraint]]
+  name = "github.com/a/exact"
+  version = "=v1.2.3"
+
+[[constraint]]
+  name = "github.com/a/tag"
+  version = "v1.4.0"
+
+[[constraint]]
+  name = "github.com/a/branch"
+  branch = "develop"
+`
+
+const testGopkgLock = `[[projects]]
+  name = "github.com/a/locked"
+  revision = "1111111111111111111111111111111111111111"
+  version = "v1.1.0"
+`
+
+func TestGopkgReadFileUnlocked(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"Gopkg.toml": testGopkgToml, "Gopkg.lock": testGopkgLock})
+	depPath := filepath.Join(dir, "Gopkg.toml")
+	p := NewGopkgParser(dir, depPath, utils.NewLogger(false))
+	_, _, _, entries := p.ReadFile(dir, depPath)
+	tests := []struct {
+		path    string
+		skipped bool
+		version string
+	}{
+		{"github.com/a/locked", false, "v1.1.0"},
+		{"github.com/a/range", true, ">= 1.0.0, < 2.0.0"},
+		// = names one version, there's nothing to lock
+		{"github.com/a/exact", false, "v1.2.3"},
+		{"github.com/a/tag", false, "v1.4.0"},
+		{"github.com/a/branch", false, "develop"},
+	}
+	for _, tt := range tests {
+		entry := entries[tt.path]
+		if entry == nil {
+			t.Errorf("%s wasn't read", tt.path)
+			continue
+		}
+		if entry.IsSkipped != tt.skipped || entry.CommitVersion != tt.version {
+			t.Errorf("%s: skipped %v version %s, want skipped %v version %s", tt.path, entry.IsSkipped, entry.CommitVersion, tt.skipped, tt.version)
+		}
+		if tt.skipped && !strings.Contains(entry.Summary, "isn't locked in Gopkg.lock") {
+			t.Errorf("%s: summary %q doesn't say it isn't locked", tt.path, entry.Summary)
+		}
+	}
+}
+
+const testGopkgUpdateToml = `[[constraint]]
+  name = "github.com/a/caret"
+  version = "^1.0.0"
+
+[[constraint]]
+  name = "github.com/a/tilde"
+  version = "~1.1.0"
+
+[[constraint]]
+  name = "github.com/a/exact"
+  version = "=v1.2.3"
+
+[[override]]
+  name = "github.com/a/revision"
+  revision = "3333333333333333333333333333333333333333"
+`
+
+const testGopkgUpdateLock = `[[projects]]
+  name = "github.com/a/caret"
+  revision = "1111111111111111111111111111111111111111"
+  version = "v1.1.0"
+
+[[projects]]
+  name = "github.com/a/tilde"
+  revision = "2222222222222222222222222222222222222222"
+  version = "v1.1.0"
+
+[[projects]]
+  name = "github.com/a/exact"
+  revision = "4444444444444444444444444444444444444444"
+  version = "v1.2.3"
+
+[[projects]]
+  name = "github.com/a/revision"
+  revision = "3333333333333333333333333333333333333333"
+
+[[projects]]
+  name = "github.com/a/transitive"
+  revision = "5555555555555555555555555555555555555555"
+  version = "v0.1.0"
+`
+
+func TestGopkgUpdateFile(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"Gopkg.toml": testGopkgUpdateToml, "Gopkg.lock": testGopkgUpdateLock})
+	depPath := filepath.Join(dir, "Gopkg.toml")
+	p := NewGopkgParser(dir, depPath, utils.NewLogger(false))
+	entries, contents, m, entryMap := p.ReadFile(dir, depPath)
+	updates := []struct {
+		path     string
+		version  string
+		revision string
+	}{
+		{"github.com/a/caret", "v1.3.0", strings.Repeat("6", 40)},
+		{"github.com/a/tilde", "v1.2.0", strings.Repeat("7", 40)},
+		{"github.com/a/exact", "v1.2.4", strings.Repeat("8", 40)},
+		{"github.com/a/revision", strings.Repeat("9", 40), strings.Repeat("9", 40)},
+		{"github.com/a/transitive", "v0.2.0", strings.Repeat("a", 40)},
+	}
+	for _, u := range updates {
+		entry := entryMap[u.path]
+		entry.IsUpdated, entry.NewCommitVersion, entry.NewRevision = false, u.version, u.revision
+	}
+	p.UpdateFile(entries, contents, m, entryMap)
+
+	// only the constraints the new versions fall outside of change
+	wantToml := strings.NewReplacer(`"~1.1.0"`, `"~1.2.0"`, `"=v1.2.3"`, `"=v1.2.4"`, strings.Repeat("3", 40), strings.Repeat("9", 40)).Replace(testGopkgUpdateToml)
+	if got := readFixture(t, dir, "Gopkg.toml"); got != wantToml {
+		t.Errorf("Gopkg.toml is now:\n%s\nwant:\n%s", got, wantToml)
+	}
+	// every update is locked
+	wantLock := strings.NewReplacer(
+		strings.Repeat("1", 40)+"\"\n  version = \"v1.1.0", strings.Repeat("6", 40)+"\"\n  version = \"v1.3.0",
+		strings.Repeat("2", 40)+"\"\n  version = \"v1.1.0", strings.Repeat("7", 40)+"\"\n  version = \"v1.2.0",
+		strings.Repeat("4", 40)+"\"\n  version = \"v1.2.3", strings.Repeat("8", 40)+"\"\n  version = \"v1.2.4",
+		strings.Repeat("3", 40), strings.Repeat("9", 40),
+		strings.Repeat("5", 40)+"\"\n  version = \"v0.1.0", strings.Repeat("a", 40)+"\"\n  version = \"v0.2.0",
+	).Replace(testGopkgUpdateLock)
+	if got := readFixture(t, dir, "Gopkg.lock"); got != wantLock {
+		t.Errorf("Gopkg.lock is now:\n%s\nwant:\n%s", got, wantLock)
+	}
+}
+
+func TestIsRange(t *testing.T) {
+	for constraint, want := range map[string]bool{"v1.2.3": false, "=v1.2.3": false, "= 1.2.3": false, "^1.2.0": true, "~1.2": true, ">= 1.0.0, < 2.0.0": true, "<=1.2.3": true, "1.x": true, "*": true} {
+		if got := isRange(constraint); got != want {
+			t.Errorf("isRange(%q) = %v, want %v", constraint, got, want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+type GovendorParser struct {
+	gitRoot string
+	depPath string
+	logger  *utils.Logger
+}
+
+func NewGovendorParser(gitRoot, depPath string, logger *utils.Logger) *GovendorParser {
+	return &GovendorParser{gitRoot, depPath, logger}
+}
+
+func (p *GovendorParser) GitRoot() string {
+	return p.gitRoot
+}
+
+func (p *GovendorParser) DepPath() string {
+	return p.depPath
+}
+
+// parse - vendor.json is kept as generic maps so fields this tool doesn't know about are written back untouched
+func (p *GovendorParser) parse(contents string) (map[string]interface{}, []map[string]interface{}) {
+	vendor := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewBufferString(contents))
+	decoder.UseNumber()
+	if err := decoder.Decode(&vendor); err != nil {
+		p.logger.PanicWithMessage("failed to parse %s. error: %v", p.depPath, err)
+	}
+	packages := make([]map[string]interface{}, 0)
+	list, _ := vendor["package"].([]interface{})
+	for _, item := range list {
+		if pkg, ok := item.(map[string]interface{}); ok {
+			packages = append(packages, pkg)
+		}
+	}
+	return vendor, packages
+}
+
+func govendorString(pkg map[string]interface{}, key string) string {
+	s, _ := pkg[key].(string)
+	return s
+}
+
+func (p *GovendorParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[string]string, map[string]*Entry) {
+	entries := make([]*Entry, 0)
+	contents := utils.ReadFileContents(godepsPath, p.logger)
+	p.logger.LogDebug("got file contents %s", contents)
+	m := make(map[string]string)
+	me := make(map[string]*Entry)
+	_, packages := p.parse(contents)
+	for _, pkg := range packages {
+		pkgPath := govendorString(pkg, "path")
+		revision := govendorString(pkg, "revision")
+		root := RepoRoot(pkgPath)
+		if entry, ok := me[root]; ok {
+			entry.SubPackages = append(entry.SubPackages, pkgPath)
+			if entry.LockedRevision != revision {
+				p.logger.LogInfo("packages of %s are vendored at different revisions (%s, %s); using %s", root, entry.LockedRevision, revision, entry.LockedRevision)
+			}
+			continue
+		}
+		entry := NewEntry(root, revision, "")
+		entry.GitType = Commit
+		entry.LockedRevision = revision
+		entry.SubPackages = []string{pkgPath}
+		// revisionTime is written with every update
+		entry.RecordsCommitTime = true
+		if origin := govendorString(pkg, "origin"); origin != "" {
+			// fetched from another location, e.g. a fork
+			entry.EffectivePath = RepoRoot(origin)
+			entry.ReplacePath = origin
+		}
+		if version := govendorString(pkg, "version"); version != "" {
+			entry.Constraint = version
+			entry.ConstraintKind = "version"
+		}
+		if exact := govendorString(pkg, "versionExact"); exact != "" {
+			entry.GitType = Tag
+			entry.CommitVersion = exact
+		}
+		m[root] = pkgPath
+		me[root] = entry
+		entries = append(entries, entry)
+	}
+	return entries, contents, m, me
+}
+
+func (p *GovendorParser) UpdateFile(entries []*Entry, content string, contentMap map[string]string, entryMap map[string]*Entry) {
+	needUpdate := false
+	vendor, packages := p.parse(content)
+	for _, pkg := range packages {
+		pkgPath := govendorString(pkg, "path")
+		entry, ok := entryMap[RepoRoot(pkgPath)]
+		if !ok || !ShouldUpdate(entry) || entry.NewRevision == "" {
+			continue
+		}
+		if entry.NewCommitTime.IsZero() {
+			p.logger.LogInfo("not updating %s: commit time of %s is unknown", pkgPath, entry.NewRevision)
+			continue
+		}
+		p.logger.LogInfo("updating package %s of entry %s", pkgPath, entry.Path)
+		pkg["revision"] = entry.NewRevision
+		pkg["revisionTime"] = entry.NewCommitTime.UTC().Format(time.RFC3339)
+		if entry.GitType == Tag {
+			// a version that pinned the exact tag moves with it, a constraint or no version at all is kept
+			if version := govendorString(pkg, "version"); version != "" && version == govendorString(pkg, "versionExact") {
+				pkg["version"] = entry.NewCommitVersion
+			}
+			pkg["versionExact"] = entry.NewCommitVersion
+		}
+		needUpdate = true
+	}
+	if needUpdate {
+		// govendor writes sorted keys with tab indentation, same as encoding a map
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "\t")
+		if err := encoder.Encode(vendor); err != nil {
+			p.logger.PanicWithMessage("failed to encode %s. error: %v", p.depPath, err)
+		}
+		content = buf.String()
+		p.logger.LogDebug("content is now:ֿ\n%s", content)
+		p.logger.LogInfo("Updating file; run `govendor sync` to refresh the checksums")
+		utils.WriteFile(p.DepPath(), content, p.logger)
+	} else {
+		p.logger.LogInfo("File already updated")
+	}
+}
@@ -0,0 +1,147 @@
+package parsers
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+const testVendorJSON = `{
+	"comment": "",
+	"ignore": "test",
+	"package": [
+		{
+			"checksumSHA1": "abc=",
+			"path": "github.com/a/pinned",
+			"revision": "1111111111111111111111111111111111111111",
+			"revisionTime": "2017-01-01T00:00:00Z",
+			"version": "v1.2.0",
+			"versionExact": "v1.2.0"
+		},
+		{
+			"checksumSHA1": "def=",
+			"path": "github.com/a/pinned/sub",
+			"revision": "1111111111111111111111111111111111111111",
+			"revisionTime": "2017-01-01T00:00:00Z",
+			"version": "v1.2.0",
+			"versionExact": "v1.2.0"
+		},
+		{
+			"path": "github.com/a/constrained",
+			"revision": "2222222222222222222222222222222222222222",
+			"revisionTime": "2017-01-01T00:00:00Z",
+			"version": "v1",
+			"versionExact": "v1.0.0"
+		},
+		{
+			"path": "github.com/a/exact",
+			"revision": "3333333333333333333333333333333333333333",
+			"revisionTime": "2017-01-01T00:00:00Z",
+			"versionExact": "v2.0.0"
+		},
+		{
+			"path": "github.com/a/untagged",
+			"revision": "5555555555555555555555555555555555555555",
+			"revisionTime": "2017-01-01T00:00:00Z"
+		},
+		{
+			"origin": "github.com/fork/lib/pkg",
+			"path": "github.com/a/lib/pkg",
+			"revision": "4444444444444444444444444444444444444444",
+			"revisionTime": "2017-01-01T00:00:00Z"
+		}
+	],
+	"rootPath": "example.com/app"
+}
+`
+
+func TestGovendorReadFile(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"vendor/vendor.json": testVendorJSON})
+	depPath := filepath.Join(dir, "vendor", "vendor.json")
+	entries, _, _, entryMap := NewGovendorParser(dir, depPath, utils.NewLogger(false)).ReadFile(dir, depPath)
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want one per repository", len(entries))
+	}
+	tests := []struct {
+		path       string
+		gitType    EntryType
+		version    string
+		constraint string
+		effective  string
+	}{
+		{"github.com/a/pinned", Tag, "v1.2.0", "v1.2.0", "github.com/a/pinned"},
+		{"github.com/a/constrained", Tag, "v1.0.0", "v1", "github.com/a/constrained"},
+		{"github.com/a/exact", Tag, "v2.0.0", "", "github.com/a/exact"},
+		{"github.com/a/untagged", Commit, strings.Repeat("5", 40), "", "github.com/a/untagged"},
+		{"github.com/a/lib", Commit, strings.Repeat("4", 40), "", "github.com/fork/lib"},
+	}
+	for _, tt := range tests {
+		entry := entryMap[tt.path]
+		if entry == nil {
+			t.Errorf("%s wasn't read", tt.path)
+			continue
+		}
+		if !entry.RecordsCommitTime {
+			t.Errorf("%s: the time of its revision isn't asked for", tt.path)
+		}
+		if entry.GitType != tt.gitType || entry.CommitVersion != tt.version || entry.Constraint != tt.constraint || entry.RepoPath() != tt.effective {
+			t.Errorf("%s: type %v version %s constraint %q repo %s, want type %v version %s constraint %q repo %s", tt.path,
+				entry.GitType, entry.CommitVersion, entry.Constraint, entry.RepoPath(), tt.gitType, tt.version, tt.constraint, tt.effective)
+		}
+	}
+	if got := entryMap["github.com/a/pinned"].SubPackages; !reflect.DeepEqual(got, []string{"github.com/a/pinned", "github.com/a/pinned/sub"}) {
+		t.Errorf("packages of github.com/a/pinned = %v", got)
+	}
+}
+
+func TestGovendorUpdateFile(t *testing.T) {
+	dir := writeFixture(t, map[string]string{"vendor/vendor.json": testVendorJSON})
+	depPath := filepath.Join(dir, "vendor", "vendor.json")
+	p := NewGovendorParser(dir, depPath, utils.NewLogger(false))
+	entries, contents, m, entryMap := p.ReadFile(dir, depPath)
+	commitTime := time.Date(2018, 2, 3, 4, 5, 6, 0, time.UTC)
+	for path, version := range map[string]string{"github.com/a/pinned": "v1.3.0", "github.com/a/constrained": "v1.1.0", "github.com/a/exact": "v2.1.0"} {
+		entry := entryMap[path]
+		entry.IsUpdated, entry.NewCommitVersion, entry.NewRevision, entry.NewCommitTime = false, version, strings.Repeat("9", 40), commitTime
+	}
+	// an untagged entry that moves to a tag
+	untagged := entryMap["github.com/a/untagged"]
+	untagged.IsUpdated, untagged.GitType, untagged.NewCommitVersion, untagged.NewRevision, untagged.NewCommitTime = false, Tag, "v0.5.0", strings.Repeat("9", 40), commitTime
+	// without a commit time the entry can't be written
+	lib := entryMap["github.com/a/lib"]
+	lib.IsUpdated, lib.NewRevision = false, strings.Repeat("8", 40)
+	p.UpdateFile(entries, contents, m, entryMap)
+
+	updated := readFixture(t, dir, "vendor/vendor.json")
+	vendor := struct {
+		Ignore   string
+		RootPath string
+		Package  []map[string]string
+	}{}
+	if err := json.Unmarshal([]byte(updated), &vendor); err != nil {
+		t.Fatal(err)
+	}
+	if vendor.Ignore != "test" || vendor.RootPath != "example.com/app" || len(vendor.Package) != 6 {
+		t.Fatalf("fields other than the packages changed:\n%s", updated)
+	}
+	newRevision := strings.Repeat("9", 40)
+	want := []map[string]string{
+		// a version pinning the exact tag moves with it, the checksum is left for govendor sync
+		{"checksumSHA1": "abc=", "path": "github.com/a/pinned", "revision": newRevision, "revisionTime": "2018-02-03T04:05:06Z", "version": "v1.3.0", "versionExact": "v1.3.0"},
+		{"checksumSHA1": "def=", "path": "github.com/a/pinned/sub", "revision": newRevision, "revisionTime": "2018-02-03T04:05:06Z", "version": "v1.3.0", "versionExact": "v1.3.0"},
+		// a constraint is kept
+		{"path": "github.com/a/constrained", "revision": newRevision, "revisionTime": "2018-02-03T04:05:06Z", "version": "v1", "versionExact": "v1.1.0"},
+		// no version is added to entries that didn't have one
+		{"path": "github.com/a/exact", "revision": newRevision, "revisionTime": "2018-02-03T04:05:06Z", "versionExact": "v2.1.0"},
+		{"path": "github.com/a/untagged", "revision": newRevision, "revisionTime": "2018-02-03T04:05:06Z", "versionExact": "v0.5.0"},
+		{"origin": "github.com/fork/lib/pkg", "path": "github.com/a/lib/pkg", "revision": strings.Repeat("4", 40), "revisionTime": "2017-01-01T00:00:00Z"},
+	}
+	if !reflect.DeepEqual(vendor.Package, want) {
+		t.Errorf("got packages\n%v\nwant\n%v", vendor.Package, want)
+	}
+}
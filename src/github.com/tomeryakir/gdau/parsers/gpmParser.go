@@ -3,6 +3,7 @@ package parsers
 import (
 	"strings"
 
+	git "github.com/tomeryakir/gdau/gitutils"
 	"github.com/tomeryakir/gdau/utils"
 )
 
@@ -43,14 +44,17 @@ func (p *GPMParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[
 		if len(tokens) > 2 && strings.HasPrefix(tokens[2], "git.remote") {
 			gitRemote = strings.Replace(tokens[2], "git.remote=", "", -1)
 		}
-		entry := NewEntry(tokens[0], tokens[1], gitRemote)
-		if strings.HasPrefix(line, "git@") {
-			p.logger.LogInfo("packages with @ in their paths aren't supported (yet). line: %s", line)
-			entry.IsSkipped = true
-			entry.Summary = "packages with @ in their paths aren't supported (yet)"
+		importPath := tokens[0]
+		if git.IsSSHRemote(importPath) {
+			// the package is given by its ssh remote
+			importPath = git.ImportPathFromRemote(tokens[0])
+			if gitRemote == "" {
+				gitRemote = tokens[0]
+			}
 		}
-		m[tokens[0]] = line
-		me[tokens[0]] = entry
+		entry := NewEntry(importPath, tokens[1], gitRemote)
+		m[importPath] = line
+		me[importPath] = entry
 		entries = append(entries, entry)
 	}
 	return entries, contents, m, me
@@ -59,7 +63,7 @@ func (p *GPMParser) ReadFile(gitRoot, godepsPath string) ([]*Entry, string, map[
 func (p *GPMParser) UpdateFile(entries []*Entry, content string, contentMap map[string]string, entryMap map[string]*Entry) {
 	needUpdate := false
 	for _, entry := range entries {
-		if !entry.IsUpdated {
+		if ShouldUpdate(entry) {
 			p.logger.LogInfo("updating entry %v", entry)
 			old := contentMap[entry.Path]
 			new := strings.Replace(old, entry.CommitVersion, entry.NewCommitVersion, 1)
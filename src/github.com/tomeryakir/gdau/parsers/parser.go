@@ -2,29 +2,79 @@ package parsers
 
 import (
 	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tomeryakir/gdau/config"
 )
 
 type Entry struct {
 	Path                 string
+	EffectivePath        string
+	ReplacePath          string
+	ExcludedVersions     []string
+	ModuleMajor          int
+	AllowsIncompatible   bool
+	SubPackages          []string
 	CommitVersion        string
+	Constraint           string
+	ConstraintKind       string
+	VersionRange         string
+	LockedRevision       string
+	Branch               string
+	TrackHead            bool
+	Policy               string
+	PreReleases          string
+	ReleaseSuffixes      []string
+	Rule                 *config.Rule
 	GitRemote            string
 	GitType              EntryType
 	IsUpdated            bool
 	IsSkipped            bool
 	IsProblem            bool
+	IsIndirect           bool
+	IsOverride           bool
+	RecordsCommitTime    bool
 	RemoteURL            string
 	ReleasesURL          string
+	Describe             string
 	NewCommitVersion     string
+	LatestVersion        string
+	NewRevision          string
+	RepoDir              string
 	NewCommitDateSummary string
+	NewCommitTime        time.Time
+	CommitsBehind        int
+	RejectedVersions     []string
 	DiffURL              string
+	CommitLog            []string
+	MoreCommits          int
+	Changelog            string
+	APIStatus            string
+	APIChanges           []string
+	AffectedCode         []string
+	Verification         string
+	VerifyOutput         string
 	Summary              string
 }
 
+// results of verifying an update by building (and testing) the project with it
+const (
+	VerifyPassed     = "passed"
+	VerifyFailed     = "failed"
+	VerifyUnverified = "unverified"
+)
+
 type EntryType int
 
 const (
-	Commit        EntryType = 0
-	BranchVersion EntryType = 1
+	// Commit - pinned to a revision
+	Commit EntryType = 0
+	// Tag - pinned to a release tag
+	Tag EntryType = 1
+	// Branch - pinned to a branch by name
+	Branch EntryType = 2
 )
 
 func NewEntry(path, commitVersion, gitRemote string) *Entry {
@@ -35,7 +85,7 @@ func NewEntry(path, commitVersion, gitRemote string) *Entry {
 	if isHexString(commitVersion) {
 		g.GitType = Commit
 	} else {
-		g.GitType = BranchVersion
+		g.GitType = Tag
 	}
 	if g.GitRemote != "" {
 		g.RemoteURL = g.GitRemote
@@ -46,6 +96,48 @@ func NewEntry(path, commitVersion, gitRemote string) *Entry {
 	return g
 }
 
+// setRemote - fetch the entry from another remote than its import path
+func (e *Entry) setRemote(remote string) {
+	if remote == "" {
+		return
+	}
+	e.GitRemote = remote
+	e.RemoteURL = remote
+}
+
+// RepoPath - the import path that is actually fetched and analyzed for the entry
+func (e *Entry) RepoPath() string {
+	if e.EffectivePath != "" {
+		return e.EffectivePath
+	}
+	return e.Path
+}
+
+// hosts whose repositories live at host/owner/repo
+var threeElementHosts = []string{"github.com", "bitbucket.org", "gitlab.com", "golang.org"}
+
+// RepoRoot - the repository an import path belongs to, e.g. golang.org/x/net for golang.org/x/net/context
+func RepoRoot(importPath string) string {
+	if i := strings.Index(importPath, ".git/"); i >= 0 {
+		return importPath[:i+len(".git")]
+	}
+	elements := strings.Split(importPath, "/")
+	size := 2
+	for _, host := range threeElementHosts {
+		if elements[0] == host {
+			size = 3
+		}
+	}
+	if elements[0] == "gopkg.in" && len(elements) > 1 && !strings.Contains(elements[1], ".v") {
+		// gopkg.in/user/pkg.v1
+		size = 3
+	}
+	if len(elements) < size {
+		return importPath
+	}
+	return strings.Join(elements[:size], "/")
+}
+
 func isHexString(s string) bool {
 	_, err := hex.DecodeString(s)
 	return err == nil
@@ -62,6 +154,41 @@ type Parser interface {
 	DepPath() string
 }
 
+// ApplyConfig - apply the matching rule of the project configuration to every entry
+func ApplyConfig(entries []*Entry, cfg *config.Config) {
+	for _, entry := range entries {
+		rule := cfg.RuleFor(entry.Path)
+		if rule == nil {
+			continue
+		}
+		entry.Rule = rule
+		if rule.Ignore {
+			entry.IsSkipped = true
+			entry.Summary = fmt.Sprintf("ignored by %s", cfg.Path)
+			continue
+		}
+		entry.setRemote(rule.Remote)
+		if rule.TrackHead {
+			entry.TrackHead = true
+		}
+		if rule.Branch != "" {
+			// compared with the head of the branch instead of the releases
+			entry.Branch = rule.Branch
+		}
+		if rule.Policy != "" {
+			entry.Policy = rule.Policy
+		}
+		if rule.PreReleases != "" {
+			entry.PreReleases = rule.PreReleases
+		}
+	}
+}
+
+// ShouldUpdate - whether the update of an entry is written to the dependency file; when updates are verified, only the ones that passed are
+func ShouldUpdate(entry *Entry) bool {
+	return !entry.IsUpdated && !entry.IsProblem && !entry.IsSkipped && (entry.Verification == "" || entry.Verification == VerifyPassed)
+}
+
 func ReadDependencyFile(p Parser) ([]*Entry, string, map[string]string, map[string]*Entry) {
 	return p.ReadFile(p.GitRoot(), p.DepPath())
 }
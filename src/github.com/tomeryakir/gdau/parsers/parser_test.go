@@ -0,0 +1,58 @@
+package parsers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// writeFixture - write files (name -> contents, names may have directories) into a temporary directory that is removed when the test ends
+func writeFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "gdau-parsers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readFixture - the contents of a fixture file
+func readFixture(t *testing.T, dir, name string) string {
+	t.Helper()
+	return utils.ReadFileContents(filepath.Join(dir, filepath.FromSlash(name)), utils.NewLogger(false))
+}
+
+func TestRepoRoot(t *testing.T) {
+	tests := []struct {
+		importPath string
+		want       string
+	}{
+		{"github.com/foo/bar", "github.com/foo/bar"},
+		{"github.com/foo/bar/sub/pkg", "github.com/foo/bar"},
+		{"golang.org/x/net/context", "golang.org/x/net"},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml.v2"},
+		{"gopkg.in/yaml.v2/sub", "gopkg.in/yaml.v2"},
+		{"gopkg.in/user/pkg.v1/sub", "gopkg.in/user/pkg.v1"},
+		{"example.com/repo.git/sub", "example.com/repo.git"},
+		{"example.com/repo/sub", "example.com/repo"},
+		{"github.com/foo", "github.com/foo"},
+	}
+	for _, tt := range tests {
+		if got := RepoRoot(tt.importPath); got != tt.want {
+			t.Errorf("RepoRoot(%s) = %s, want %s", tt.importPath, got, tt.want)
+		}
+	}
+}
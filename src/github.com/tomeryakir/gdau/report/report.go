@@ -1,9 +1,10 @@
 package report
 
 import (
+	"html/template"
 	"os"
 	"os/exec"
-	"text/template"
+	"sort"
 
 	dep "github.com/tomeryakir/gdau/parsers"
 )
@@ -12,6 +13,24 @@ const (
 	reportFile = "report.html"
 )
 
+// Manifest - a dependency file found in the repository and its analyzed entries
+type Manifest struct {
+	Path    string
+	Entries []*dep.Entry
+}
+
+// Pin - the version one manifest pins a library at
+type Pin struct {
+	Manifest string
+	Version  string
+}
+
+// Drift - a library that different manifests pin at different versions
+type Drift struct {
+	Path string
+	Pins []Pin
+}
+
 type reportData struct {
 	UptodatePackages int
 	OutdatedPackages int
@@ -20,7 +39,14 @@ type reportData struct {
 	Entries          []*dep.Entry
 }
 
-func GenerateReportFile(entries []*dep.Entry) error {
+type repositoryReportData struct {
+	reportData
+	Root      string
+	Manifests []*Manifest
+	Drifts    []*Drift
+}
+
+func countEntries(entries []*dep.Entry) reportData {
 	data := reportData{0, 0, 0, 0, entries}
 	for _, entry := range entries {
 		if entry.IsSkipped {
@@ -33,15 +59,68 @@ func GenerateReportFile(entries []*dep.Entry) error {
 			data.OutdatedPackages++
 		}
 	}
+	return data
+}
+
+// reportTemplate - the report of a single dependency file; html/template escapes what the entries carry from upstream, e.g. commit subjects and build output
+func reportTemplate() *template.Template {
+	return template.Must(template.New("dependencies").Parse(string(GetHtmlTemplateBinData())))
+}
+
+// repositoryReportTemplate - the report of all the dependency files of a repository
+func repositoryReportTemplate() *template.Template {
+	return template.Must(template.New("repository").Parse(string(GetRepositoryHtmlTemplateBinData())))
+}
 
-	tmpl := template.Must(template.New("dependencies").Parse(string(GetHtmlTemplateBinData())))
+// writeReportFile - render the report into the report file
+func writeReportFile(tmpl *template.Template, data interface{}) error {
 	f, err := os.Create(reportFile)
 	if err != nil {
 		return err
 	}
-	tmpl.Execute(f, data)
-	return nil
+	if err := tmpl.Execute(f, data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
 
+func GenerateReportFile(entries []*dep.Entry) error {
+	return writeReportFile(reportTemplate(), countEntries(entries))
+}
+
+// GenerateRepositoryReportFile - one report for all the manifests of a repository, grouped by manifest
+func GenerateRepositoryReportFile(root string, manifests []*Manifest) error {
+	all := make([]*dep.Entry, 0)
+	for _, m := range manifests {
+		all = append(all, m.Entries...)
+	}
+	data := repositoryReportData{countEntries(all), root, manifests, findDrifts(manifests)}
+	return writeReportFile(repositoryReportTemplate(), data)
+}
+
+// findDrifts - libraries that aren't pinned at the same version across the manifests
+func findDrifts(manifests []*Manifest) []*Drift {
+	pins := make(map[string][]Pin)
+	versions := make(map[string]map[string]bool)
+	for _, m := range manifests {
+		for _, entry := range m.Entries {
+			library := entry.RepoPath()
+			if versions[library] == nil {
+				versions[library] = make(map[string]bool)
+			}
+			versions[library][entry.CommitVersion] = true
+			pins[library] = append(pins[library], Pin{m.Path, entry.CommitVersion})
+		}
+	}
+	drifts := make([]*Drift, 0)
+	for library, v := range versions {
+		if len(v) > 1 {
+			drifts = append(drifts, &Drift{library, pins[library]})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Path < drifts[j].Path })
+	return drifts
 }
 
 func OpenReportFile() {
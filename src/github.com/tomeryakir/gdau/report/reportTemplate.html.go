@@ -9,69 +9,120 @@ import (
 // GetHtmlTemplateBinData returns raw, uncompressed file data.
 func GetHtmlTemplateBinData() []byte {
 	gz, err := gzip.NewReader(bytes.NewBuffer([]byte{
-		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xac, 0x56,
-		0xdd, 0x6e, 0xb3, 0x46, 0x10, 0xbd, 0xef, 0x53, 0xac, 0xb8, 0x6a, 0x55,
-		0x99, 0x6d, 0x62, 0xab, 0x5f, 0x5a, 0xad, 0xb9, 0x89, 0x2b, 0xb5, 0x51,
-		0x12, 0x27, 0x4e, 0xdc, 0xa6, 0xbd, 0xa9, 0x16, 0x18, 0xc3, 0xd6, 0xb0,
-		0x8b, 0x76, 0xc6, 0x76, 0x2c, 0xe4, 0x77, 0xaf, 0xf8, 0xf1, 0x0f, 0x2d,
-		0x76, 0x70, 0xfc, 0xdd, 0x20, 0x76, 0x38, 0x73, 0x38, 0x33, 0xbb, 0x73,
-		0x40, 0xc4, 0x94, 0x26, 0xde, 0x37, 0x8c, 0x31, 0x26, 0x62, 0x90, 0x61,
-		0x75, 0x5b, 0x2e, 0x49, 0x51, 0x02, 0xde, 0x08, 0x32, 0xd0, 0x21, 0xe8,
-		0x60, 0xcd, 0x26, 0x90, 0x19, 0x4b, 0x82, 0x57, 0x0f, 0xf6, 0xc0, 0x44,
-		0xe9, 0x39, 0xb3, 0x90, 0x0c, 0x1d, 0xa4, 0x75, 0x02, 0x18, 0x03, 0x90,
-		0xc3, 0x62, 0x0b, 0xb3, 0xa1, 0x13, 0x13, 0x65, 0xf8, 0x33, 0xe7, 0x48,
-		0x32, 0x98, 0x67, 0x92, 0x62, 0xd7, 0x37, 0x86, 0x90, 0xac, 0xcc, 0x82,
-		0x50, 0xbb, 0x81, 0x49, 0xf9, 0x2e, 0xc0, 0x07, 0x6e, 0xdf, 0xbd, 0xe2,
-		0x01, 0xe2, 0x3e, 0xe6, 0xa6, 0x4a, 0xbb, 0x01, 0xa2, 0xc3, 0x94, 0x26,
-		0x88, 0xac, 0xa2, 0xf5, 0xd0, 0xc1, 0x58, 0xf6, 0x6f, 0x06, 0xbd, 0x28,
-		0x1a, 0xaf, 0x27, 0x3f, 0xa8, 0xb7, 0x5b, 0xff, 0xe1, 0x79, 0xd9, 0x7f,
-		0x53, 0x59, 0x2a, 0xfb, 0x83, 0x87, 0xd1, 0xf7, 0xe1, 0xaf, 0xfc, 0x6a,
-		0xf6, 0xfc, 0xe5, 0x66, 0xc0, 0xff, 0xf9, 0x31, 0xf8, 0x93, 0xab, 0xbb,
-		0xd7, 0xe7, 0xe9, 0x38, 0x0e, 0xfe, 0xb0, 0x5f, 0xde, 0x7f, 0xba, 0x5b,
-		0x9a, 0xc9, 0xfb, 0xeb, 0xf5, 0xc3, 0x5f, 0xab, 0xab, 0x57, 0x87, 0x05,
-		0xd6, 0x20, 0x1a, 0xab, 0x22, 0xa5, 0x87, 0x8e, 0xd4, 0x46, 0xaf, 0x53,
-		0xb3, 0x40, 0xa7, 0xee, 0x07, 0xdf, 0x37, 0x44, 0xf8, 0x26, 0x5c, 0x1f,
-		0x94, 0x1c, 0x5f, 0xb7, 0x35, 0x26, 0xbe, 0x3e, 0x80, 0x84, 0x6a, 0xb9,
-		0x5f, 0x55, 0x49, 0x03, 0xef, 0x65, 0x91, 0xa6, 0xd2, 0xae, 0x05, 0x8f,
-		0x07, 0xff, 0x79, 0x88, 0x99, 0xd4, 0x2c, 0x48, 0x24, 0xe2, 0xd0, 0xf1,
-		0x65, 0x18, 0x01, 0x2b, 0xaf, 0x3d, 0x5c, 0x04, 0x01, 0x20, 0x3a, 0x5e,
-		0x9e, 0xbb, 0xd3, 0x8c, 0x4c, 0x28, 0x09, 0x9e, 0x64, 0x30, 0x97, 0x11,
-		0xe0, 0x66, 0xc3, 0x16, 0x59, 0x8f, 0x4c, 0xaf, 0x08, 0xb2, 0xac, 0x8e,
-		0x0a, 0x5e, 0x70, 0x75, 0xa4, 0x5f, 0x49, 0xab, 0x95, 0x8e, 0x4a, 0xfa,
-		0xf1, 0x82, 0x0a, 0xa2, 0xf0, 0x80, 0xde, 0x2c, 0xa8, 0x67, 0x66, 0x17,
-		0xf0, 0x87, 0x52, 0x47, 0x60, 0x4b, 0xfa, 0x27, 0x6b, 0xfc, 0x04, 0xd2,
-		0x03, 0xf6, 0xcc, 0x9a, 0xa2, 0x36, 0xa5, 0x23, 0x06, 0xd6, 0x1a, 0x7b,
-		0x1e, 0xb5, 0xd2, 0x33, 0x53, 0x12, 0xbf, 0xcc, 0x55, 0x96, 0x35, 0x64,
-		0x63, 0x15, 0x39, 0x2a, 0x59, 0xf0, 0xc6, 0xee, 0x08, 0xdf, 0xf2, 0x8f,
-		0x76, 0x6e, 0x04, 0x24, 0x55, 0x82, 0x2d, 0x3b, 0x47, 0xd2, 0x4f, 0x60,
-		0x2b, 0xb0, 0x5a, 0x94, 0xd7, 0x5e, 0x6c, 0x96, 0x60, 0xeb, 0x7b, 0x4c,
-		0x99, 0xd3, 0xcc, 0xab, 0x72, 0x8b, 0x23, 0xb6, 0xcb, 0x2d, 0x16, 0xbd,
-		0x50, 0xda, 0x79, 0x0b, 0xb4, 0x86, 0x7b, 0x75, 0x95, 0x82, 0x53, 0x7c,
-		0x1c, 0xf4, 0x42, 0x92, 0x16, 0x78, 0x1a, 0x33, 0x4e, 0x42, 0xf6, 0x3b,
-		0x58, 0x54, 0x46, 0x9f, 0x06, 0x3e, 0xc2, 0xaa, 0x1b, 0xf0, 0x5e, 0x12,
-		0x20, 0xb1, 0x5b, 0x93, 0xa6, 0x8a, 0xd8, 0x48, 0xd2, 0x47, 0x2a, 0xb7,
-		0xc3, 0xd0, 0x06, 0x2a, 0xa2, 0x0d, 0x3f, 0xda, 0x67, 0x16, 0xb3, 0xb8,
-		0xed, 0x19, 0xa6, 0x32, 0x49, 0x8e, 0xb4, 0x2b, 0xcf, 0x6d, 0x71, 0xfa,
-		0x98, 0xfb, 0x8b, 0x26, 0xab, 0x8a, 0x83, 0xd1, 0x0a, 0xab, 0x48, 0x6d,
-		0x3b, 0xc7, 0x1e, 0x10, 0x7a, 0x42, 0xd6, 0xa6, 0x96, 0xe7, 0xee, 0x04,
-		0x52, 0x43, 0x30, 0x9d, 0xdc, 0x6f, 0x36, 0x0e, 0x23, 0x69, 0x23, 0xa0,
-		0xa1, 0xf3, 0xb7, 0x9f, 0x48, 0x3d, 0xaf, 0x0e, 0xbb, 0xa4, 0x78, 0xb3,
-		0x11, 0x5c, 0x7a, 0xac, 0x99, 0x96, 0x80, 0x44, 0xc0, 0x23, 0x89, 0xa2,
-		0x2c, 0xc7, 0xfb, 0x76, 0x0b, 0xfb, 0x4e, 0xf0, 0x2a, 0x52, 0x11, 0x71,
-		0x6a, 0xe9, 0x47, 0xb3, 0x62, 0x35, 0x63, 0xee, 0x6f, 0x58, 0x4f, 0xc4,
-		0x89, 0x82, 0x1b, 0x75, 0x9d, 0x9e, 0xb0, 0x9a, 0xac, 0x9e, 0xa1, 0x2e,
-		0x22, 0x20, 0x41, 0x60, 0x95, 0x92, 0x7a, 0xe8, 0x2f, 0x55, 0xb2, 0xb5,
-		0x91, 0x9a, 0xee, 0x73, 0x5a, 0xa6, 0x59, 0x69, 0x6f, 0x97, 0x6a, 0xd9,
-		0x39, 0xf2, 0x74, 0xe7, 0xbd, 0x67, 0xea, 0xb9, 0x54, 0xc1, 0xce, 0xb4,
-		0xb7, 0x8e, 0x7d, 0xce, 0xfb, 0xf5, 0x47, 0x0d, 0x28, 0x5e, 0x9d, 0xe7,
-		0x6e, 0x35, 0xc6, 0xf5, 0xe0, 0x17, 0x47, 0xb9, 0xd3, 0xe1, 0xd3, 0x86,
-		0x3e, 0xd1, 0xea, 0x83, 0x09, 0x19, 0xa9, 0xd9, 0xec, 0xf8, 0x58, 0x3d,
-		0xc2, 0xea, 0x7f, 0xba, 0x64, 0x87, 0xba, 0x0f, 0xea, 0xda, 0x51, 0x14,
-		0x06, 0x55, 0x5b, 0x50, 0xb7, 0xf2, 0xce, 0xd9, 0xba, 0xce, 0x8a, 0xbe,
-		0xea, 0x9e, 0x75, 0x2c, 0x47, 0xf0, 0x63, 0x76, 0x77, 0xec, 0x5d, 0x82,
-		0x53, 0xf3, 0x0f, 0xa8, 0x0e, 0x16, 0xdf, 0xb6, 0xd6, 0xcf, 0xaa, 0xe0,
-		0x15, 0x5e, 0xf0, 0xf2, 0x07, 0xf3, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff,
-		0x19, 0xac, 0x5e, 0x25, 0x67, 0x0a, 0x00, 0x00,
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xc4, 0x58,
+		0xdf, 0x6e, 0xdb, 0xb6, 0x17, 0xbe, 0xff, 0x3d, 0x05, 0xa1, 0xab, 0x04,
+		0x3f, 0x58, 0x5a, 0x9a, 0x60, 0xed, 0x0a, 0x46, 0x40, 0x9b, 0x0c, 0x58,
+		0x8a, 0xa4, 0x49, 0x9d, 0xa6, 0xeb, 0x76, 0x33, 0x50, 0xe2, 0xb1, 0xc4,
+		0x9a, 0x22, 0x35, 0x92, 0x72, 0x6a, 0x08, 0xba, 0xdf, 0x5e, 0x73, 0x4f,
+		0x32, 0x90, 0xa2, 0x64, 0x4b, 0xb1, 0x5d, 0xb7, 0x2e, 0xb0, 0x2a, 0x70,
+		0x25, 0xf2, 0x9c, 0x4f, 0x1f, 0x3f, 0x9e, 0x3f, 0xb4, 0x71, 0x6e, 0x0a,
+		0x1e, 0xff, 0x0f, 0x21, 0x84, 0x70, 0x0e, 0x84, 0xb6, 0xb7, 0xf6, 0xc2,
+		0x86, 0x19, 0x0e, 0xf1, 0x25, 0x94, 0x20, 0x28, 0x88, 0x74, 0x89, 0xa6,
+		0x50, 0x4a, 0x65, 0x70, 0xd4, 0x4e, 0xac, 0x0c, 0x39, 0x13, 0x73, 0xa4,
+		0x80, 0x9f, 0x07, 0xda, 0x2c, 0x39, 0xe8, 0x1c, 0xc0, 0x04, 0x28, 0x57,
+		0x30, 0x3b, 0x0f, 0x72, 0x63, 0x4a, 0xfd, 0x32, 0x8a, 0xb4, 0x21, 0xe9,
+		0xbc, 0x24, 0x26, 0x0f, 0x13, 0x29, 0x8d, 0x36, 0x8a, 0x94, 0x29, 0x15,
+		0x61, 0x2a, 0x8b, 0xa8, 0x1f, 0x88, 0xce, 0xc2, 0xd3, 0xf0, 0x24, 0x4a,
+		0xb5, 0x5e, 0x8d, 0x85, 0x05, 0x13, 0x61, 0xaa, 0x75, 0x80, 0x98, 0x30,
+		0x90, 0x29, 0x66, 0x96, 0xe7, 0x81, 0xce, 0xc9, 0xe9, 0x8b, 0xb3, 0x49,
+		0x96, 0xdd, 0x2e, 0xa7, 0x3f, 0xb0, 0x8f, 0x17, 0xc9, 0xcd, 0xbb, 0xc5,
+		0xe9, 0x47, 0x56, 0x16, 0xe4, 0xf4, 0xec, 0xe6, 0xf2, 0xff, 0xf4, 0x97,
+		0xe8, 0x64, 0xf6, 0xee, 0xf9, 0x8b, 0xb3, 0xe8, 0xd3, 0x8f, 0xe9, 0x6f,
+		0x11, 0x7b, 0xf3, 0xfe, 0xdd, 0xc3, 0x6d, 0x9e, 0xfe, 0xaa, 0x9e, 0x7f,
+		0xfe, 0xe9, 0xcd, 0x42, 0x4e, 0x3f, 0xbf, 0x7f, 0x76, 0xf3, 0xfb, 0xe3,
+		0xc9, 0xfb, 0x00, 0xa5, 0x4a, 0x6a, 0x2d, 0x15, 0xcb, 0x98, 0x38, 0x0f,
+		0x88, 0x90, 0x62, 0x59, 0xc8, 0x4a, 0x07, 0x5e, 0x8f, 0x68, 0x25, 0x08,
+		0x4e, 0x24, 0x5d, 0xae, 0x2d, 0x39, 0x7f, 0xb6, 0x49, 0x98, 0xfc, 0xd9,
+		0x9a, 0x09, 0x65, 0x8b, 0xd5, 0x93, 0xbd, 0x70, 0x7e, 0x16, 0xdf, 0x57,
+		0x45, 0x41, 0xd4, 0x12, 0x47, 0xf9, 0xd9, 0x68, 0x52, 0x97, 0x44, 0xa0,
+		0x94, 0x13, 0xad, 0xcf, 0x83, 0x84, 0xd0, 0x0c, 0x90, 0xfb, 0x9c, 0xe8,
+		0x2a, 0x4d, 0x41, 0xeb, 0x20, 0xae, 0xeb, 0xf0, 0xa1, 0x34, 0x92, 0x12,
+		0x03, 0x77, 0x24, 0x9d, 0x93, 0x0c, 0x74, 0xd3, 0xa0, 0xaa, 0x9c, 0x18,
+		0x39, 0xb1, 0x83, 0xa8, 0xf4, 0xa3, 0x38, 0xb2, 0x58, 0x7b, 0xc2, 0x3f,
+		0x12, 0x25, 0x98, 0xc8, 0x1c, 0xfc, 0x6d, 0x65, 0x2c, 0x10, 0x5d, 0x83,
+		0x97, 0x95, 0x99, 0xc8, 0xd9, 0x01, 0xf8, 0x94, 0x88, 0x0c, 0x94, 0x83,
+		0xbf, 0x53, 0x32, 0xe1, 0x50, 0xac, 0xa1, 0x97, 0x4a, 0xda, 0xb5, 0x31,
+		0x91, 0x21, 0x50, 0x4a, 0xaa, 0xaf, 0x83, 0x66, 0x62, 0x26, 0x1d, 0xf0,
+		0xfd, 0x9c, 0x95, 0xe5, 0x80, 0xb6, 0x6e, 0x47, 0xb6, 0x52, 0xc6, 0xd1,
+		0x60, 0x77, 0x70, 0xa2, 0xa2, 0x2f, 0xed, 0xdc, 0x25, 0x18, 0xc2, 0xb8,
+		0xde, 0xb0, 0x73, 0x86, 0x24, 0x1c, 0x3a, 0x82, 0xed, 0x83, 0xfb, 0x9c,
+		0xe4, 0x72, 0x01, 0xca, 0xdf, 0xeb, 0x02, 0x05, 0x43, 0x3f, 0xfb, 0x87,
+		0x8d, 0x0d, 0xb1, 0xde, 0xd7, 0x3e, 0x4c, 0x28, 0x51, 0xf3, 0x0d, 0xa6,
+		0xde, 0x3c, 0xf6, 0xab, 0xc4, 0x91, 0xc9, 0xb7, 0x1b, 0xdd, 0x1b, 0x62,
+		0x2a, 0xbd, 0xdb, 0xe6, 0x96, 0x53, 0xf4, 0x01, 0x94, 0x66, 0x52, 0xec,
+		0x36, 0x7c, 0x0b, 0x8f, 0xfb, 0x19, 0x5e, 0x13, 0x03, 0xda, 0xa0, 0x0b,
+		0x59, 0x14, 0xcc, 0xa0, 0x4b, 0x62, 0x60, 0xb7, 0x7d, 0x9f, 0x0c, 0x9b,
+		0x8c, 0xac, 0xeb, 0xa0, 0x1e, 0x75, 0x17, 0x36, 0x36, 0x17, 0x3b, 0xcd,
+		0x74, 0x41, 0x38, 0xdf, 0x22, 0x57, 0x5d, 0x2b, 0x1b, 0x7d, 0x28, 0xfc,
+		0x59, 0x18, 0xc5, 0x6c, 0x60, 0x6c, 0x34, 0xb3, 0x7f, 0xd8, 0xa8, 0xcd,
+		0x18, 0xdd, 0x3f, 0x6c, 0x68, 0x8c, 0x89, 0x2f, 0x6a, 0x75, 0x1d, 0x4e,
+		0xa1, 0x90, 0x06, 0x1e, 0xa6, 0xd7, 0x4d, 0x13, 0x20, 0x43, 0x54, 0x06,
+		0xe6, 0x3c, 0xf8, 0x23, 0xe1, 0x44, 0xcc, 0x5d, 0x4c, 0xde, 0x11, 0x93,
+		0x37, 0x0d, 0x8e, 0x48, 0x8c, 0x86, 0x6e, 0x1c, 0x88, 0x06, 0xbd, 0xc5,
+		0x11, 0xbb, 0xe5, 0xc4, 0x47, 0x9d, 0xd9, 0x31, 0x8e, 0xda, 0x11, 0x07,
+		0x54, 0xd7, 0x6c, 0x86, 0xc2, 0x2b, 0x7d, 0x25, 0x28, 0x53, 0x90, 0x9a,
+		0xa6, 0xd9, 0x9a, 0x1b, 0x9c, 0x65, 0xb9, 0x09, 0x62, 0xe6, 0x2d, 0x7d,
+		0xfc, 0xd7, 0x35, 0x08, 0xda, 0x34, 0x2d, 0x50, 0x66, 0xd0, 0x11, 0x07,
+		0x81, 0xc2, 0xfb, 0x2a, 0xe9, 0x52, 0xe7, 0x18, 0x9d, 0x58, 0x50, 0xf7,
+		0xca, 0xa3, 0xba, 0x1e, 0x4f, 0xdb, 0x94, 0xed, 0x2c, 0x3b, 0x66, 0x03,
+		0xcc, 0x70, 0x0a, 0x25, 0x27, 0x29, 0xf8, 0xd5, 0xdb, 0xb4, 0xf2, 0x68,
+		0xaa, 0x9d, 0xa0, 0x28, 0x59, 0xa2, 0xba, 0x1e, 0xd9, 0x6d, 0x86, 0xaa,
+		0x38, 0x8c, 0x30, 0x2a, 0x0e, 0xce, 0xb9, 0x9d, 0x19, 0x7a, 0xe1, 0xc8,
+		0x6c, 0x08, 0x96, 0xf5, 0xab, 0x93, 0xcf, 0x97, 0x8b, 0x1d, 0xd1, 0xd0,
+		0x5d, 0x6e, 0xd3, 0x77, 0x97, 0x1f, 0x0f, 0xe6, 0x05, 0xde, 0x87, 0x04,
+		0x70, 0x0d, 0xa8, 0x65, 0xe2, 0x2b, 0xe2, 0xa1, 0x4c, 0xba, 0x1a, 0xeb,
+		0xe1, 0xbe, 0x8d, 0xcb, 0x43, 0x69, 0x8b, 0x3c, 0x3d, 0x94, 0x4b, 0xdf,
+		0xae, 0x1e, 0xfa, 0xc6, 0xf4, 0x95, 0x7c, 0x0e, 0x65, 0xd0, 0x77, 0xb4,
+		0xae, 0x9d, 0xf9, 0xf7, 0xbb, 0xed, 0x87, 0x3f, 0x51, 0xf8, 0xea, 0xee,
+		0xaa, 0xad, 0x8f, 0x28, 0x48, 0x14, 0x90, 0xb9, 0x35, 0x6e, 0x9a, 0x2f,
+		0xb7, 0xb0, 0xd7, 0xde, 0xb8, 0xc7, 0xeb, 0xd4, 0x1b, 0x81, 0xa6, 0xb2,
+		0x28, 0x89, 0x61, 0x09, 0x87, 0x9d, 0xb0, 0xbd, 0x52, 0x17, 0xbd, 0xfd,
+		0x0a, 0xd9, 0x46, 0x74, 0x4f, 0xf8, 0x03, 0x28, 0x36, 0x63, 0x29, 0x31,
+		0x4c, 0x0a, 0x14, 0x94, 0x44, 0x6b, 0xa0, 0xfb, 0x41, 0xb7, 0x9e, 0x40,
+		0x37, 0x52, 0x1e, 0xc2, 0xce, 0x08, 0xe3, 0x40, 0xf7, 0x12, 0xa2, 0x0d,
+		0x15, 0xe4, 0xc4, 0xd3, 0x28, 0xa9, 0x18, 0xdf, 0xe7, 0x05, 0x95, 0x58,
+		0x78, 0x36, 0xbb, 0xb9, 0x43, 0x2a, 0x05, 0x25, 0x6a, 0x19, 0xc4, 0x0f,
+		0x62, 0xf1, 0x84, 0xff, 0xbe, 0xa9, 0xee, 0x8a, 0xd2, 0x4e, 0x1b, 0x9b,
+		0xdc, 0x75, 0x1d, 0xb6, 0xdd, 0xca, 0xf7, 0x37, 0x2f, 0x7a, 0x78, 0x09,
+		0x3a, 0x55, 0x2c, 0x19, 0xd5, 0x9f, 0x7f, 0xfe, 0xfe, 0xcb, 0x96, 0x9f,
+		0xb5, 0xc9, 0x61, 0x09, 0x6a, 0x7d, 0x2f, 0xa4, 0xb0, 0xa7, 0x59, 0x26,
+		0xcc, 0xd0, 0xbb, 0x2b, 0x3f, 0xb7, 0x0b, 0x50, 0x8a, 0x51, 0x68, 0x1a,
+		0xe9, 0xef, 0xba, 0xb8, 0x4f, 0x7b, 0x4f, 0x0f, 0xf8, 0xd2, 0xbe, 0x6e,
+		0x80, 0xf7, 0x4d, 0x35, 0x4f, 0x48, 0xf3, 0x0d, 0x19, 0xbe, 0xd6, 0xb5,
+		0x2e, 0xd9, 0x6c, 0xb6, 0xbd, 0xd5, 0xbd, 0x85, 0xc7, 0x91, 0x88, 0xb6,
+		0x5b, 0x75, 0x62, 0xd8, 0x09, 0xfd, 0x1a, 0x72, 0x26, 0xe8, 0x58, 0x8f,
+		0xf1, 0x2c, 0x4a, 0xdb, 0x67, 0x94, 0x38, 0x73, 0xbb, 0xf8, 0xd7, 0x8a,
+		0x88, 0xf4, 0x69, 0x8b, 0x70, 0xe0, 0x44, 0x50, 0x14, 0xb6, 0x67, 0x0e,
+		0xff, 0x66, 0x74, 0x24, 0x60, 0x3c, 0xf4, 0x84, 0xde, 0xf1, 0x90, 0x06,
+		0x77, 0x00, 0x4e, 0xe9, 0x81, 0x63, 0xd3, 0xa0, 0x23, 0x2b, 0x1c, 0xe1,
+		0x5c, 0x3e, 0xb6, 0x7d, 0xcb, 0xe4, 0xae, 0xfd, 0xdc, 0x49, 0xce, 0xd2,
+		0xa5, 0xed, 0x85, 0xee, 0xe6, 0xf8, 0xab, 0x37, 0x65, 0x2d, 0xfa, 0x7a,
+		0x72, 0xf6, 0xb4, 0xe4, 0xcf, 0x43, 0x4d, 0xf3, 0x9d, 0x4b, 0xe5, 0xa1,
+		0x6a, 0xfd, 0xe7, 0x42, 0x7d, 0xd7, 0x84, 0xef, 0x55, 0x76, 0xb2, 0x48,
+		0xd5, 0x05, 0xe9, 0xb5, 0xcc, 0x50, 0x78, 0x91, 0xdb, 0x1a, 0xc7, 0x65,
+		0xd6, 0x34, 0x98, 0xb6, 0x27, 0xfe, 0x18, 0xeb, 0xd6, 0xa3, 0x8b, 0x98,
+		0xd6, 0x46, 0x77, 0xab, 0xc1, 0x51, 0x37, 0xbf, 0x1e, 0xf2, 0xd7, 0x0e,
+		0xa2, 0xe2, 0x5d, 0x89, 0xe3, 0x4c, 0x9b, 0x49, 0x25, 0xdc, 0x17, 0x64,
+		0x1a, 0xc4, 0xfd, 0xd1, 0x74, 0xdd, 0x9a, 0xb3, 0x18, 0xa7, 0x92, 0x82,
+		0x4d, 0x2a, 0x1b, 0x04, 0xee, 0x1e, 0x47, 0x9c, 0xad, 0x14, 0xab, 0xba,
+		0x3a, 0x72, 0x23, 0x15, 0xf8, 0xf4, 0xe9, 0x77, 0xc8, 0x26, 0x44, 0x5d,
+		0x0f, 0xa7, 0x50, 0x21, 0x15, 0x74, 0x89, 0x35, 0xda, 0x80, 0xfe, 0x3f,
+		0x36, 0x1b, 0xae, 0xbc, 0x54, 0x30, 0x3a, 0x5f, 0xd7, 0xf5, 0xc0, 0x20,
+		0x2a, 0x15, 0xac, 0x48, 0x75, 0x42, 0x0d, 0xf0, 0x5e, 0xdd, 0x5d, 0x79,
+		0xa1, 0x76, 0x48, 0xf9, 0xea, 0xee, 0x0a, 0xa5, 0xdb, 0xe4, 0xdc, 0xa6,
+		0x1e, 0xea, 0x39, 0x79, 0x0d, 0x07, 0xaf, 0xda, 0x4f, 0xc4, 0x6d, 0xa4,
+		0x67, 0x33, 0x48, 0x0d, 0xd0, 0x0b, 0x49, 0x61, 0x17, 0x6d, 0x6f, 0x86,
+		0x2c, 0x38, 0xea, 0x0e, 0xca, 0x43, 0xe7, 0xe3, 0x43, 0x16, 0x34, 0x40,
+		0x3a, 0x6c, 0x49, 0xae, 0x09, 0x2f, 0x6f, 0x2b, 0x53, 0x56, 0x66, 0xc7,
+		0x92, 0x06, 0xbd, 0x5a, 0x3a, 0xeb, 0x0d, 0x0b, 0xd8, 0x18, 0x18, 0xa3,
+		0x37, 0xb8, 0xd8, 0xd8, 0x42, 0x66, 0x0a, 0x9f, 0xdc, 0xc2, 0x7c, 0xe9,
+		0xd0, 0x3b, 0x08, 0x79, 0x51, 0x9f, 0x7a, 0x20, 0x43, 0x32, 0x8d, 0x6c,
+		0xc5, 0xb1, 0xfd, 0x92, 0x51, 0x50, 0x40, 0x37, 0x50, 0xed, 0x50, 0xbc,
+		0xa4, 0x4f, 0x71, 0x5a, 0x31, 0x6d, 0x83, 0xf6, 0x14, 0x57, 0x20, 0x23,
+		0xee, 0xbb, 0xcb, 0x0f, 0x8e, 0xb6, 0x7d, 0x77, 0xf4, 0xee, 0x4f, 0xe6,
+		0x70, 0x64, 0x86, 0x3f, 0x27, 0xf9, 0x41, 0xfb, 0x43, 0xc1, 0xc6, 0xdf,
+		0x28, 0x70, 0xd4, 0xda, 0xe3, 0x28, 0x37, 0x05, 0x8f, 0xff, 0x1d, 0x00,
+		0x78, 0x4b, 0x0f, 0x8b, 0xb4, 0x13, 0x00, 0x00,
 	}))
 
 	if err != nil {
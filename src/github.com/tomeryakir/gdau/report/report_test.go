@@ -0,0 +1,71 @@
+package report
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	dep "github.com/tomeryakir/gdau/parsers"
+)
+
+// upstreamEntry - an outdated entry whose texts come from a hostile upstream
+func upstreamEntry() *dep.Entry {
+	entry := dep.NewEntry("github.com/acme/lib", "v1.0.0", "")
+	entry.IsUpdated = false
+	entry.NewCommitVersion = "v1.1.0"
+	entry.RemoteURL = "javascript:alert(1)"
+	entry.CommitLog = []string{"abcdef1 <script>alert('log')</script>"}
+	entry.Changelog = "## v1.1.0\n- <img src=x onerror=alert('changelog')>"
+	entry.APIChanges = []string{"removed func <b>A</b>"}
+	entry.VerifyOutput = "<script>alert('build')</script>"
+	entry.Summary = "<iframe>"
+	return entry
+}
+
+func TestReportEscapesUpstreamText(t *testing.T) {
+	manifests := []*Manifest{{Path: "go.mod", Entries: []*dep.Entry{upstreamEntry()}}}
+	tests := []struct {
+		name string
+		run  func(*bytes.Buffer) error
+	}{
+		{"dependency file", func(b *bytes.Buffer) error { return reportTemplate().Execute(b, countEntries(manifests[0].Entries)) }},
+		{"repository", func(b *bytes.Buffer) error {
+			return repositoryReportTemplate().Execute(b, repositoryReportData{countEntries(manifests[0].Entries), "/repo", manifests, nil})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := tt.run(&b); err != nil {
+				t.Fatal(err)
+			}
+			html := b.String()
+			for _, raw := range []string{"<script>", "<img", "<b>", "<iframe>", `href="javascript:`} {
+				if strings.Contains(html, raw) {
+					t.Errorf("the report has %s unescaped", raw)
+				}
+			}
+			if !strings.Contains(html, "&lt;script&gt;alert(&#39;log&#39;)&lt;/script&gt;") {
+				t.Errorf("the commit log is missing from the report")
+			}
+		})
+	}
+}
+
+func TestFindDrifts(t *testing.T) {
+	forked := dep.NewEntry("github.com/acme/forked", "v1.0.0", "")
+	forked.EffectivePath = "github.com/fork/forked"
+	manifests := []*Manifest{
+		{"go.mod", []*dep.Entry{dep.NewEntry("github.com/acme/lib", "v1.0.0", ""), dep.NewEntry("github.com/acme/same", "v2.0.0", ""), forked}},
+		{"tools/Gopkg.toml", []*dep.Entry{dep.NewEntry("github.com/acme/lib", "v1.1.0", ""), dep.NewEntry("github.com/acme/same", "v2.0.0", "")}},
+		// the fork is another library
+		{"web/glide.yaml", []*dep.Entry{dep.NewEntry("github.com/acme/lib", "v1.0.0", ""), dep.NewEntry("github.com/acme/forked", "v1.1.0", "")}},
+	}
+	want := []*Drift{
+		{"github.com/acme/lib", []Pin{{"go.mod", "v1.0.0"}, {"tools/Gopkg.toml", "v1.1.0"}, {"web/glide.yaml", "v1.0.0"}}},
+	}
+	if got := findDrifts(manifests); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
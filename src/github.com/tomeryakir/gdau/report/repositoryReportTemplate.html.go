@@ -0,0 +1,152 @@
+package report
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// GetRepositoryHtmlTemplateBinData returns raw, uncompressed file data.
+func GetRepositoryHtmlTemplateBinData() []byte {
+	gz, err := gzip.NewReader(bytes.NewBuffer([]byte{
+		0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xcc, 0x58,
+		0xdd, 0x6e, 0xdc, 0xba, 0x11, 0xbe, 0x3f, 0x4f, 0x41, 0x08, 0xbd, 0xb0,
+		0x51, 0xac, 0x54, 0xc7, 0x46, 0xcf, 0x69, 0x40, 0x0b, 0x48, 0xec, 0x16,
+		0xf5, 0x81, 0x1d, 0x6f, 0x36, 0x71, 0x9a, 0xf6, 0xa6, 0xe0, 0x8a, 0xa3,
+		0x15, 0x63, 0x8a, 0x54, 0x49, 0xca, 0xce, 0x82, 0xd0, 0x7d, 0xfb, 0x9a,
+		0x7d, 0x92, 0x82, 0x14, 0xa5, 0x5d, 0x69, 0x7f, 0xbc, 0xb6, 0x53, 0xb4,
+		0x5e, 0xc3, 0x5e, 0x89, 0x33, 0x1f, 0x87, 0x1f, 0x87, 0xf3, 0x43, 0x6b,
+		0x29, 0xe4, 0x4c, 0x00, 0x8a, 0x40, 0x18, 0xc5, 0x40, 0x47, 0x4d, 0xf3,
+		0x13, 0x5a, 0xfb, 0xc1, 0x86, 0xcc, 0x39, 0xa0, 0x8c, 0x13, 0xad, 0xcf,
+		0xa3, 0xf6, 0xc1, 0xff, 0x9d, 0x14, 0xf2, 0x01, 0x54, 0xf8, 0xae, 0x4b,
+		0x14, 0xa5, 0x03, 0x3d, 0xf7, 0x8b, 0x4d, 0x01, 0x84, 0xf6, 0xba, 0xee,
+		0x61, 0x42, 0x89, 0xba, 0xdf, 0x22, 0x1a, 0xc4, 0xd3, 0x29, 0xc9, 0xee,
+		0xc9, 0x02, 0x70, 0x62, 0x8a, 0xdd, 0x42, 0x9f, 0x0c, 0x31, 0xb5, 0xde,
+		0x2f, 0x73, 0xcb, 0x29, 0xfa, 0x02, 0x4a, 0x33, 0x29, 0xf6, 0x0b, 0x7e,
+		0x80, 0xc7, 0xc3, 0x04, 0xaf, 0x89, 0x01, 0x6d, 0xd0, 0x85, 0x2c, 0x4b,
+		0x66, 0xd0, 0x25, 0x31, 0x4f, 0x59, 0x59, 0x97, 0x25, 0x51, 0xcb, 0xed,
+		0x42, 0xee, 0x2d, 0x10, 0xba, 0x65, 0xc0, 0xcc, 0x25, 0x5d, 0x76, 0x9c,
+		0xe9, 0x92, 0x70, 0xbe, 0x83, 0x2e, 0x6b, 0x15, 0x11, 0x0b, 0x40, 0x71,
+		0xd3, 0x6c, 0x1d, 0x77, 0xbf, 0xd8, 0xa8, 0xed, 0xca, 0xdd, 0x0f, 0x36,
+		0x34, 0xc5, 0x04, 0x15, 0x0a, 0xf2, 0xf3, 0xc8, 0xda, 0x78, 0x06, 0xa5,
+		0x34, 0x70, 0x37, 0xbb, 0x6e, 0x9a, 0x08, 0x19, 0xa2, 0x16, 0x60, 0xce,
+		0xa3, 0xbf, 0xcf, 0x39, 0x11, 0xf7, 0x51, 0x6a, 0x6d, 0x3c, 0x25, 0xa6,
+		0x68, 0x1a, 0x9c, 0x90, 0x14, 0x0d, 0xd5, 0x38, 0x10, 0x0d, 0x7a, 0x87,
+		0x22, 0xf6, 0xeb, 0x48, 0x8f, 0x3a, 0xb1, 0x63, 0x9c, 0xb4, 0x6f, 0x3c,
+		0x90, 0xb5, 0x2c, 0x47, 0xf1, 0x95, 0xbe, 0x12, 0x94, 0x29, 0xc8, 0x4c,
+		0xd3, 0x60, 0x5d, 0x11, 0xd1, 0x71, 0x30, 0x27, 0x74, 0x01, 0xc8, 0xff,
+		0x9d, 0x70, 0xb6, 0x28, 0x4c, 0x94, 0xb2, 0x20, 0x89, 0x13, 0x27, 0x98,
+		0x5a, 0x0b, 0x82, 0x36, 0x4d, 0x0b, 0xb4, 0x30, 0xe8, 0x88, 0x83, 0x40,
+		0xf1, 0xa7, 0x7a, 0x1e, 0xbc, 0x49, 0x1f, 0xa3, 0x13, 0x07, 0xea, 0xa7,
+		0x3c, 0xb2, 0x76, 0x3c, 0xdc, 0x34, 0xa8, 0xea, 0x24, 0x3b, 0xcb, 0x06,
+		0x98, 0xf1, 0x0c, 0x2a, 0x4e, 0x32, 0x08, 0xab, 0x9f, 0xab, 0x24, 0x0d,
+		0x68, 0xaa, 0x1d, 0xa0, 0x68, 0xbe, 0x44, 0xd6, 0x8e, 0xe4, 0xb6, 0x43,
+		0xd5, 0x1c, 0x46, 0x18, 0x35, 0x07, 0xaf, 0xdc, 0x8e, 0x0c, 0xb5, 0x70,
+		0x62, 0xb6, 0x78, 0xc9, 0xfa, 0xa7, 0xa3, 0xef, 0xd3, 0x3d, 0xab, 0x2a,
+		0xa0, 0x7b, 0xbc, 0xa1, 0xfb, 0xf8, 0x4d, 0xdf, 0x45, 0x31, 0x13, 0xb9,
+		0x8c, 0xd2, 0x00, 0x16, 0x08, 0x3e, 0xc4, 0x08, 0xe0, 0x1a, 0x50, 0x6b,
+		0xc9, 0x54, 0xc9, 0x39, 0x87, 0xf2, 0xb5, 0x96, 0x50, 0xe7, 0xde, 0x2a,
+		0x4a, 0x03, 0xdc, 0xcb, 0x6c, 0xb9, 0xab, 0x28, 0x31, 0xaf, 0x67, 0x45,
+		0xd7, 0x59, 0x06, 0x5a, 0x47, 0xe9, 0x5d, 0x35, 0x31, 0x72, 0xe2, 0x30,
+		0x9f, 0x69, 0xcf, 0x6b, 0x2d, 0x78, 0x24, 0x4a, 0x30, 0xb1, 0x88, 0xd2,
+		0xdb, 0xda, 0xb8, 0xe9, 0x69, 0x98, 0xdf, 0x6f, 0x3f, 0xfc, 0x03, 0xc5,
+		0xef, 0xa6, 0x57, 0x6d, 0x60, 0x44, 0xd1, 0x5c, 0x01, 0xb9, 0x77, 0xc2,
+		0x4d, 0x83, 0x9e, 0xa4, 0xf7, 0x7d, 0x10, 0xee, 0xf1, 0x3a, 0xf6, 0x46,
+		0xa0, 0x99, 0x2c, 0x2b, 0x62, 0xd8, 0x9c, 0xc3, 0x5e, 0xd8, 0x9e, 0xa9,
+		0x8b, 0x5e, 0x7e, 0x85, 0xec, 0x3c, 0xba, 0x37, 0xf8, 0x0b, 0x28, 0x96,
+		0xb3, 0x8c, 0x18, 0x26, 0x05, 0x8a, 0x2a, 0xa2, 0x35, 0xd0, 0xc3, 0xa0,
+		0x5b, 0x4d, 0xa0, 0x5b, 0x4d, 0x1e, 0xc2, 0xe6, 0x84, 0x71, 0xa0, 0x07,
+		0x11, 0xd1, 0xba, 0x0a, 0xf2, 0xe4, 0x69, 0x34, 0xaf, 0x19, 0x3f, 0x64,
+		0x82, 0x5a, 0x3c, 0x04, 0x6b, 0xf6, 0xdb, 0x0e, 0x99, 0x14, 0x94, 0xa8,
+		0x65, 0x94, 0xde, 0x89, 0x87, 0x0d, 0xfb, 0x0f, 0x3d, 0xea, 0x3e, 0x28,
+		0xed, 0x95, 0x71, 0x87, 0xdb, 0xda, 0xb8, 0x4d, 0x53, 0x21, 0xb1, 0x05,
+		0xd2, 0xe3, 0x4b, 0xd0, 0x99, 0x62, 0xf3, 0x51, 0xfc, 0xf9, 0xf7, 0xbf,
+		0xfe, 0xe9, 0xc2, 0xcf, 0xda, 0xe0, 0x30, 0x04, 0xb5, 0xba, 0x17, 0x52,
+		0x68, 0xa3, 0x08, 0x13, 0x66, 0xa8, 0xdd, 0x85, 0x9f, 0xdb, 0x07, 0x50,
+		0x8a, 0x51, 0x68, 0x1a, 0x19, 0xbe, 0x75, 0x7e, 0x9f, 0xf5, 0x9a, 0x01,
+		0xf0, 0xad, 0x9b, 0x6e, 0x80, 0xf7, 0xa2, 0x98, 0x27, 0xa4, 0x79, 0xc1,
+		0x09, 0x5f, 0xcb, 0x5a, 0x97, 0x2c, 0xcf, 0x77, 0xa7, 0xba, 0x0f, 0xf0,
+		0x38, 0x22, 0xd1, 0x65, 0xab, 0x8e, 0x0c, 0x37, 0xa0, 0xdf, 0x43, 0xc1,
+		0x04, 0x1d, 0xf3, 0x31, 0x1e, 0x45, 0x59, 0xfb, 0x8c, 0xe6, 0x5e, 0xdc,
+		0x2d, 0xfe, 0xbd, 0x22, 0x22, 0xdb, 0x4c, 0x11, 0x1e, 0x9c, 0x08, 0x8a,
+		0xe2, 0xb6, 0xd8, 0x08, 0x33, 0xa3, 0x23, 0x01, 0xe3, 0x57, 0x1b, 0xe6,
+		0x1d, 0x0f, 0xcd, 0xe0, 0x1e, 0xc0, 0x33, 0x3d, 0x50, 0x6c, 0x1a, 0x74,
+		0xe4, 0x88, 0x23, 0x9c, 0xcb, 0xc7, 0x36, 0x6f, 0x99, 0xc2, 0xa7, 0x9f,
+		0xa9, 0xe4, 0x2c, 0x5b, 0xba, 0x5c, 0xe8, 0xbf, 0x1c, 0x3f, 0x7b, 0x53,
+		0xd6, 0xbc, 0xaf, 0x37, 0xce, 0x95, 0x49, 0xa1, 0x10, 0x6a, 0x9a, 0x1f,
+		0x1c, 0x2a, 0x5f, 0xcb, 0xd6, 0xff, 0x9c, 0xa8, 0x1f, 0x7a, 0xe0, 0x7b,
+		0x96, 0x3d, 0x2d, 0x52, 0x75, 0x4e, 0x7a, 0x2d, 0x17, 0x28, 0xbe, 0x28,
+		0x5c, 0x8c, 0xe3, 0x72, 0xd1, 0x34, 0x98, 0x82, 0x21, 0x8c, 0xeb, 0x14,
+		0xeb, 0x56, 0xa3, 0xf3, 0x98, 0x56, 0x46, 0x77, 0xab, 0xc1, 0x49, 0x37,
+		0xbe, 0xee, 0xf2, 0xd7, 0x1e, 0xa2, 0xe6, 0x5d, 0x88, 0xe3, 0x4c, 0x9b,
+		0x49, 0x2d, 0xb4, 0x59, 0xba, 0x28, 0x9b, 0xf6, 0x35, 0xe9, 0xba, 0x34,
+		0x67, 0x29, 0xce, 0x24, 0x05, 0x77, 0xa8, 0x9c, 0x13, 0xf8, 0xef, 0x38,
+		0xe1, 0x6c, 0xc5, 0x58, 0xdd, 0xc5, 0x91, 0x1b, 0xa9, 0x20, 0x1c, 0x9f,
+		0x7e, 0x87, 0xdc, 0x81, 0xb0, 0x76, 0x38, 0x84, 0x4a, 0xa9, 0xa0, 0x3b,
+		0x58, 0xa3, 0x0d, 0xe8, 0xff, 0xb1, 0x7c, 0xb8, 0xf2, 0x4a, 0xc1, 0xa8,
+		0xb0, 0xb6, 0x76, 0x20, 0x90, 0x54, 0x0a, 0x56, 0x46, 0x75, 0x44, 0x0d,
+		0xf0, 0xde, 0x4d, 0xaf, 0x02, 0x51, 0x7b, 0xa8, 0x7c, 0x37, 0xbd, 0x42,
+		0xd9, 0x2e, 0x3a, 0x77, 0xb1, 0x87, 0x7a, 0x9b, 0x02, 0x87, 0x83, 0xa9,
+		0x0e, 0x23, 0x71, 0x97, 0xd1, 0x79, 0x0e, 0x99, 0x01, 0x7a, 0x21, 0x29,
+		0xec, 0x33, 0x3b, 0x88, 0x21, 0x07, 0x8e, 0xba, 0x42, 0x79, 0xa8, 0x7c,
+		0xfc, 0x9a, 0x05, 0x0d, 0x90, 0x5e, 0xb7, 0x24, 0x9f, 0x84, 0x97, 0xb7,
+		0xb5, 0xa9, 0x6a, 0xb3, 0x67, 0x49, 0x83, 0x5c, 0x2d, 0xbd, 0xf4, 0x96,
+		0x05, 0x6c, 0x75, 0x8c, 0xd1, 0x0c, 0xde, 0x37, 0x76, 0x18, 0x33, 0x83,
+		0x6f, 0x7e, 0x61, 0x21, 0x74, 0xe8, 0x3d, 0x06, 0x05, 0x52, 0x37, 0x35,
+		0x90, 0x21, 0x0b, 0x8d, 0x5c, 0xc4, 0x71, 0xf9, 0x92, 0x51, 0x50, 0x40,
+		0xb7, 0x98, 0xda, 0xa1, 0x04, 0x4a, 0x37, 0x71, 0x5a, 0x32, 0x5d, 0x82,
+		0x0e, 0x26, 0xae, 0x40, 0x46, 0xb6, 0xef, 0x0f, 0x3f, 0x38, 0xd9, 0xd5,
+		0x3b, 0x06, 0xf5, 0x8d, 0x31, 0x9c, 0xf8, 0xde, 0x75, 0xa8, 0x84, 0x13,
+		0x7f, 0x43, 0x90, 0xfe, 0xd4, 0x69, 0xe1, 0xc2, 0x94, 0xbc, 0x95, 0xc1,
+		0xc3, 0x16, 0x18, 0x1b, 0x66, 0x38, 0xa4, 0x33, 0xa8, 0xa4, 0x66, 0x46,
+		0xaa, 0x25, 0xba, 0x84, 0x0a, 0x04, 0x05, 0x91, 0x2d, 0x91, 0x7b, 0xab,
+		0x0c, 0x4e, 0x5a, 0x99, 0x95, 0x0e, 0x67, 0xe2, 0x1e, 0x29, 0xe0, 0xe7,
+		0x91, 0x8f, 0x41, 0xba, 0x00, 0x30, 0x51, 0x48, 0xf1, 0x85, 0x31, 0x95,
+		0x7e, 0x9b, 0x24, 0xda, 0x90, 0xec, 0xbe, 0x22, 0xa6, 0x88, 0xe7, 0x52,
+		0x1a, 0x57, 0x8b, 0x54, 0x19, 0x15, 0x71, 0x26, 0xcb, 0xa4, 0x7f, 0x91,
+		0x9c, 0xc5, 0xa7, 0xf1, 0x49, 0x92, 0x69, 0xbd, 0x7a, 0x17, 0x97, 0x4c,
+		0xc4, 0x99, 0xd6, 0x11, 0x62, 0xc2, 0xc0, 0x42, 0x31, 0xb3, 0x3c, 0x8f,
+		0x74, 0x41, 0x4e, 0x7f, 0x39, 0x9b, 0x2c, 0x16, 0xb7, 0xcb, 0xd9, 0xef,
+		0xd8, 0xd7, 0x8b, 0xf9, 0xcd, 0xc7, 0x87, 0xd3, 0xaf, 0xac, 0x2a, 0xc9,
+		0xe9, 0xd9, 0xcd, 0xe5, 0x6f, 0xe9, 0x9f, 0x93, 0x93, 0xfc, 0xe3, 0xcf,
+		0xbf, 0x9c, 0x25, 0xdf, 0x7e, 0x9f, 0xfd, 0x35, 0x61, 0xbf, 0x7e, 0xfe,
+		0x78, 0x77, 0x5b, 0x64, 0x7f, 0x51, 0x3f, 0x7f, 0xff, 0xc3, 0xaf, 0x0f,
+		0x72, 0xf6, 0xfd, 0xf3, 0x9b, 0x9b, 0xbf, 0x3d, 0x9e, 0x7c, 0x8e, 0x50,
+		0xa6, 0xa4, 0xd6, 0x52, 0xb1, 0x05, 0x13, 0xe7, 0x11, 0x11, 0x52, 0x2c,
+		0x4b, 0x59, 0xeb, 0xd0, 0xec, 0xe3, 0x64, 0xc5, 0x0d, 0x1e, 0xb2, 0x8a,
+		0x8b, 0x37, 0xe9, 0x06, 0x31, 0x68, 0xe2, 0x32, 0xd3, 0x4c, 0x4a, 0x77,
+		0x18, 0x92, 0xe2, 0xcd, 0x9a, 0x38, 0x65, 0x0f, 0xab, 0x27, 0xf7, 0xc1,
+		0xc5, 0xd9, 0xea, 0x72, 0xa2, 0x38, 0x1b, 0x0d, 0x1e, 0x50, 0xc0, 0x06,
+		0x1f, 0xbe, 0x21, 0x82, 0xe5, 0xa0, 0x7d, 0x38, 0xa6, 0x2b, 0x83, 0x72,
+		0xc6, 0x7d, 0xd8, 0x73, 0x95, 0xed, 0x81, 0xd0, 0x5d, 0x5d, 0x6f, 0x6d,
+		0x7c, 0x57, 0x19, 0xe9, 0xea, 0xb9, 0xb5, 0xde, 0xbc, 0xee, 0x5b, 0xae,
+		0xbe, 0x4d, 0x7f, 0x16, 0x7c, 0xdf, 0x39, 0x59, 0x1b, 0x77, 0xcd, 0xd3,
+		0x1a, 0xbc, 0xac, 0xcd, 0x44, 0xe6, 0xaf, 0xc0, 0xef, 0xfa, 0x07, 0x57,
+		0x43, 0xb5, 0xad, 0xea, 0x1a, 0x7a, 0xa5, 0xa4, 0x5b, 0x1b, 0x13, 0x0b,
+		0x04, 0x4a, 0x49, 0xf5, 0x3c, 0xe8, 0xb6, 0x19, 0x77, 0xd9, 0xbd, 0xed,
+		0xc7, 0xd7, 0x80, 0x75, 0xfb, 0x66, 0xa7, 0xc9, 0x38, 0x19, 0x6c, 0xbc,
+		0xaf, 0x0d, 0xfb, 0xa7, 0xd0, 0x0e, 0x28, 0x96, 0xbb, 0xdd, 0x7b, 0xc2,
+		0x57, 0x42, 0x6c, 0x41, 0x5e, 0x7c, 0x8b, 0xc7, 0xfc, 0x1f, 0xde, 0x10,
+		0xae, 0x9d, 0x8f, 0x3f, 0x31, 0xfe, 0x84, 0x70, 0x58, 0xdf, 0x7f, 0xff,
+		0xa2, 0x6e, 0x83, 0xef, 0xf1, 0xc7, 0xda, 0xdf, 0xb8, 0x30, 0x85, 0xde,
+		0x9e, 0xa3, 0x70, 0xd3, 0xb6, 0x47, 0x34, 0x80, 0x4e, 0x99, 0xd8, 0x07,
+		0x79, 0xd0, 0x2d, 0xe0, 0xaa, 0x8c, 0xf4, 0xf3, 0x3f, 0xa7, 0x86, 0xb5,
+		0xb6, 0x8f, 0x03, 0xcf, 0x54, 0x0b, 0xb4, 0x1f, 0xa2, 0xb5, 0x3b, 0x17,
+		0x3d, 0x55, 0x24, 0xbf, 0x2c, 0x57, 0x1d, 0x78, 0x86, 0x86, 0xd0, 0xfd,
+		0x8e, 0xac, 0xc5, 0xc5, 0x27, 0x4e, 0xd6, 0xda, 0x85, 0xea, 0xf8, 0x54,
+		0x59, 0x6b, 0xa0, 0xac, 0x5c, 0x73, 0xb2, 0xba, 0x9a, 0x47, 0xf1, 0x1f,
+		0xdb, 0x4b, 0xfa, 0xa6, 0x79, 0xbe, 0x89, 0x38, 0x69, 0xd3, 0x08, 0x4e,
+		0x0a, 0x53, 0xf2, 0xf4, 0x3f, 0x03, 0x00, 0xb8, 0x02, 0xeb, 0x54, 0xf9,
+		0x17, 0x00, 0x00,
+	}))
+
+	if err != nil {
+		panic("Decompression failed: " + err.Error())
+	}
+
+	var b bytes.Buffer
+	io.Copy(&b, gz)
+	gz.Close()
+
+	return b.Bytes()
+}
@@ -0,0 +1,317 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version - a parsed semantic version; Numbers holds major, minor, patch (and an optional fourth part)
+type Version struct {
+	Original   string
+	Numbers    []int
+	PreRelease []string
+	Build      string
+}
+
+// Parse - parse a version such as v1.2.3, 1.2, 1.2.3-rc.1+meta
+func Parse(v string) (*Version, error) {
+	ver := &Version{Original: v}
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		ver.Build = s[i+1:]
+		s = s[:i]
+	}
+	if i := strings.Index(s, "-"); i >= 0 {
+		if s[i+1:] == "" {
+			return nil, fmt.Errorf("invalid version %s: empty pre-release", v)
+		}
+		ver.PreRelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 4 {
+		return nil, fmt.Errorf("invalid version %s: too many parts", v)
+	}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %s", v)
+		}
+		ver.Numbers = append(ver.Numbers, n)
+	}
+	for len(ver.Numbers) < 3 {
+		ver.Numbers = append(ver.Numbers, 0)
+	}
+	return ver, nil
+}
+
+// Major - major version number
+func (v *Version) Major() int {
+	return v.Numbers[0]
+}
+
+// Minor - minor version number
+func (v *Version) Minor() int {
+	return v.Numbers[1]
+}
+
+// Patch - patch version number
+func (v *Version) Patch() int {
+	return v.Numbers[2]
+}
+
+// IsPreRelease - whether the version carries pre-release identifiers
+func (v *Version) IsPreRelease() bool {
+	return len(v.PreRelease) > 0
+}
+
+// IsRelease - whether the version is a release: it has no pre-release identifiers, or its first one is a release suffix,
+// e.g. devops for a project that releases v1.0.0-devops
+func (v *Version) IsRelease(releaseSuffixes []string) bool {
+	if !v.IsPreRelease() {
+		return true
+	}
+	for _, suffix := range releaseSuffixes {
+		if v.PreRelease[0] == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare - returns -1, 0 or 1 by semver precedence; build metadata is ignored
+func (v *Version) Compare(o *Version) int {
+	for i := 0; i < len(v.Numbers) || i < len(o.Numbers); i++ {
+		a, b := numberAt(v.Numbers, i), numberAt(o.Numbers, i)
+		if a != b {
+			return compareInts(a, b)
+		}
+	}
+	switch {
+	case !v.IsPreRelease() && !o.IsPreRelease():
+		return 0
+	case !v.IsPreRelease():
+		return 1
+	case !o.IsPreRelease():
+		return -1
+	}
+	for i := 0; i < len(v.PreRelease) && i < len(o.PreRelease); i++ {
+		if c := comparePreRelease(v.PreRelease[i], o.PreRelease[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(v.PreRelease), len(o.PreRelease))
+}
+
+func numberAt(n []int, i int) int {
+	if i < len(n) {
+		return n[i]
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func comparePreRelease(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Satisfies - whether a version matches a dep-style constraint ("^1.2.0", "~1.2", ">=1.0, <2.0", "1.2.x", "1.2.0 || 2.0.0").
+// A bare version is treated as a caret range, same as dep does.
+func Satisfies(constraint, version string) (bool, error) {
+	v, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+	for _, alternative := range strings.Split(constraint, "||") {
+		ok := true
+		for _, c := range strings.Split(alternative, ",") {
+			matched, err := satisfiesOne(strings.TrimSpace(c), v)
+			if err != nil {
+				return false, err
+			}
+			if !matched {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func satisfiesOne(c string, v *Version) (bool, error) {
+	if c == "" || c == "*" {
+		return true, nil
+	}
+	op := ""
+	for _, candidate := range []string{">=", "<=", "!=", "=", ">", "<", "~", "^"} {
+		if strings.HasPrefix(c, candidate) {
+			op = candidate
+			break
+		}
+	}
+	bound := strings.TrimSpace(strings.TrimPrefix(c, op))
+	if i := strings.IndexAny(bound, "xX*"); i >= 0 {
+		// 1.2.x is the same as ~1.2
+		bound = strings.TrimSuffix(bound[:i], ".")
+		if op == "" {
+			op = "~"
+		}
+	}
+	b, err := Parse(bound)
+	if err != nil {
+		return false, fmt.Errorf("invalid constraint %s: %v", c, err)
+	}
+	// the parts left out of the bound are open, so ~1 and ^0 allow any minor version
+	numbers := bound
+	if i := strings.IndexAny(numbers, "-+"); i >= 0 {
+		numbers = numbers[:i]
+	}
+	parts := strings.Count(numbers, ".") + 1
+	cmp := v.Compare(b)
+	switch op {
+	case "=":
+		return cmp == 0, nil
+	case "!=":
+		return cmp != 0, nil
+	case ">":
+		return cmp > 0, nil
+	case "<":
+		return cmp < 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case "~":
+		if cmp < 0 {
+			return false, nil
+		}
+		if parts == 1 {
+			return v.Major() == b.Major(), nil
+		}
+		return v.Major() == b.Major() && v.Minor() == b.Minor(), nil
+	}
+	// caret: same left-most non-zero part
+	if cmp < 0 {
+		return false, nil
+	}
+	if b.Major() != 0 || parts == 1 {
+		return v.Major() == b.Major(), nil
+	}
+	if b.Minor() != 0 || parts == 2 {
+		return v.Major() == 0 && v.Minor() == b.Minor(), nil
+	}
+	return v.Major() == 0 && v.Minor() == 0 && v.Patch() == b.Patch(), nil
+}
+
+// update policies - how far an update may move from the current version
+const (
+	PolicyPatch = "patch"
+	PolicyMinor = "minor"
+	PolicyMajor = "major"
+)
+
+// Policies - the supported update policies
+func Policies() []string {
+	return []string{PolicyPatch, PolicyMinor, PolicyMajor}
+}
+
+// IsPolicy - whether s is a supported update policy
+func IsPolicy(s string) bool {
+	for _, p := range Policies() {
+		if p == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WithinPolicy - whether updating from current to candidate stays within the policy
+func WithinPolicy(policy string, current, candidate *Version) bool {
+	switch policy {
+	case PolicyPatch:
+		return candidate.Major() == current.Major() && candidate.Minor() == current.Minor()
+	case PolicyMinor:
+		return candidate.Major() == current.Major()
+	}
+	return true
+}
+
+// pre-release handling - which pre-release versions updates may move to
+const (
+	PreReleaseStable   = "stable"
+	PreReleaseAllow    = "allow"
+	PreReleaseIfPinned = "if-pinned"
+)
+
+// PreReleaseModes - the supported pre-release handling modes
+func PreReleaseModes() []string {
+	return []string{PreReleaseStable, PreReleaseAllow, PreReleaseIfPinned}
+}
+
+// IsPreReleaseMode - whether s is a supported pre-release handling mode
+func IsPreReleaseMode(s string) bool {
+	for _, m := range PreReleaseModes() {
+		if m == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsPreReleases - whether the mode allows pre-releases; current is nil when the current version isn't a semantic version.
+// Versions with one of the release suffixes aren't pre-releases.
+func AllowsPreReleases(mode string, current *Version, releaseSuffixes []string) bool {
+	switch mode {
+	case PreReleaseAllow:
+		return true
+	case PreReleaseIfPinned:
+		return current != nil && !current.IsRelease(releaseSuffixes)
+	}
+	return false
+}
+
+// WithinCeiling - whether v is at most the ceiling; parts left out of the ceiling are open, so v1 allows any v1.x.y
+func WithinCeiling(ceiling string, v *Version) (bool, error) {
+	c, err := Parse(ceiling)
+	if err != nil {
+		return false, err
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(ceiling), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := len(strings.Split(s, "."))
+	for i := 0; i < parts; i++ {
+		a, b := numberAt(v.Numbers, i), numberAt(c.Numbers, i)
+		if a != b {
+			return a < b, nil
+		}
+	}
+	if parts < 3 || !c.IsPreRelease() {
+		return true, nil
+	}
+	return v.Compare(c) <= 0, nil
+}
@@ -0,0 +1,242 @@
+package semver
+
+import "testing"
+
+func mustParse(t *testing.T, v string) *Version {
+	t.Helper()
+	version, err := Parse(v)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", v, err)
+	}
+	return version
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		v          string
+		numbers    []int
+		preRelease int
+		build      string
+		err        bool
+	}{
+		{v: "v1.2.3", numbers: []int{1, 2, 3}},
+		{v: "1.2", numbers: []int{1, 2, 0}},
+		{v: "v2", numbers: []int{2, 0, 0}},
+		{v: "1.2.3.4", numbers: []int{1, 2, 3, 4}},
+		{v: "1.2.3-rc.1", numbers: []int{1, 2, 3}, preRelease: 2},
+		{v: "1.2.3-rc.1+build.5", numbers: []int{1, 2, 3}, preRelease: 2, build: "build.5"},
+		{v: "1.2.3+build-1", numbers: []int{1, 2, 3}, build: "build-1"},
+		{v: "1.2.3-", err: true},
+		{v: "1.2.3.4.5", err: true},
+		{v: "release-1", err: true},
+		{v: "1.x", err: true},
+		{v: "", err: true},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.v)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Parse(%q) = %+v, want an error", tt.v, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.v, err)
+			continue
+		}
+		if len(v.Numbers) != len(tt.numbers) || len(v.PreRelease) != tt.preRelease || v.Build != tt.build {
+			t.Errorf("Parse(%q) = %+v", tt.v, v)
+			continue
+		}
+		for i := range tt.numbers {
+			if v.Numbers[i] != tt.numbers[i] {
+				t.Errorf("Parse(%q) = %v, want %v", tt.v, v.Numbers, tt.numbers)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"v1.0.0", "1.0", 0},
+		{"1.0.0", "1.0.1", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.2.3.4", "1.2.3", 1},
+		// pre-releases come before their release
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0", "1.0.0-rc.1", 1},
+		{"1.0.0-rc.1", "0.9.9", 1},
+		// identifiers compare numerically, numbers before text, and a longer list wins a tie
+		{"1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"1.0.0-alpha", "1.0.0-beta", -1},
+		{"1.0.0-1", "1.0.0-alpha", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.beta", "1.0.0-alpha.1", 1},
+		// build metadata doesn't count
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"1.0.0+build", "1.0.0", 0},
+		{"1.0.0-rc.1+build", "1.0.0-rc.1", 0},
+		{"1.0.0-rc.1+build", "1.0.0", -1},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.a).Compare(mustParse(t, tt.b)); got != tt.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{"^1.2.0", "1.2.0", true},
+		{"^1.2.0", "v1.9.3", true},
+		{"^1.2.0", "1.1.9", false},
+		{"^1.2.0", "2.0.0", false},
+		{"^0.8.0", "0.8.5", true},
+		{"^0.8.0", "0.9.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0", "0.5.0", true},
+		{"^0.0", "0.0.7", true},
+		{"^0.0", "0.1.0", false},
+		{"1.2.0", "1.5.0", true},
+		{"1.2.0", "2.0.0", false},
+		{"~1.2.0", "1.2.9", true},
+		{"~1.2.0", "1.3.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~1.2.3", "1.2.2", false},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{"1.2.x", "1.2.7", true},
+		{"1.2.x", "1.3.0", false},
+		{"1.x", "1.8.0", true},
+		{"*", "3.0.0", true},
+		{">= 1.0.0, < 2.0.0", "1.5.0", true},
+		{">= 1.0.0, < 2.0.0", "2.0.0", false},
+		{">= 1.0.0, < 2.0.0", "0.9.0", false},
+		{">1.0.0", "1.0.0", false},
+		{"<=1.0.0", "1.0.0", true},
+		{"=v1.2.3", "1.2.3", true},
+		{"=v1.2.3", "1.2.4", false},
+		{"!=1.2.3", "1.2.4", true},
+		{"^1.0.0 || ^3.0.0", "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", "2.1.0", false},
+		{">=1.0.0-rc.1", "1.0.0-rc.2", true},
+		{"^1.2.0", "1.2.0+build", true},
+	}
+	for _, tt := range tests {
+		got, err := Satisfies(tt.constraint, tt.version)
+		if err != nil || got != tt.want {
+			t.Errorf("Satisfies(%q, %s) = %v, %v, want %v", tt.constraint, tt.version, got, err, tt.want)
+		}
+	}
+	for _, tt := range [][2]string{{"^foo", "1.0.0"}, {"^1.0.0", "latest"}, {">= 1.0.0, < two", "1.0.0"}} {
+		if _, err := Satisfies(tt[0], tt[1]); err == nil {
+			t.Errorf("Satisfies(%q, %s) succeeded, want an error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestWithinCeiling(t *testing.T) {
+	tests := []struct {
+		ceiling string
+		version string
+		want    bool
+	}{
+		{"v1", "1.9.9", true},
+		{"v1", "2.0.0", false},
+		{"v1", "0.3.0", true},
+		{"v1.4", "1.4.9", true},
+		{"v1.4", "1.5.0", false},
+		{"v1.4.2", "1.4.2", true},
+		{"v1.4.2", "1.4.3", false},
+		{"v1.4.2", "1.4.2-rc.1", true},
+		{"v1", "2.0.0-rc.1", false},
+		{"v2.0.0-rc.2", "2.0.0-rc.1", true},
+		{"v2.0.0-rc.2", "2.0.0", false},
+		{"v1.4", "1.4.0+build", true},
+	}
+	for _, tt := range tests {
+		got, err := WithinCeiling(tt.ceiling, mustParse(t, tt.version))
+		if err != nil || got != tt.want {
+			t.Errorf("WithinCeiling(%s, %s) = %v, %v, want %v", tt.ceiling, tt.version, got, err, tt.want)
+		}
+	}
+	if _, err := WithinCeiling("latest", mustParse(t, "1.0.0")); err == nil {
+		t.Errorf("WithinCeiling(latest) succeeded, want an error")
+	}
+}
+
+func TestWithinPolicy(t *testing.T) {
+	tests := []struct {
+		policy    string
+		candidate string
+		want      bool
+	}{
+		{PolicyPatch, "1.2.9", true},
+		{PolicyPatch, "1.3.0", false},
+		{PolicyMinor, "1.9.0", true},
+		{PolicyMinor, "2.0.0", false},
+		{PolicyMajor, "3.0.0", true},
+	}
+	current := mustParse(t, "1.2.3")
+	for _, tt := range tests {
+		if got := WithinPolicy(tt.policy, current, mustParse(t, tt.candidate)); got != tt.want {
+			t.Errorf("WithinPolicy(%s, 1.2.3, %s) = %v, want %v", tt.policy, tt.candidate, got, tt.want)
+		}
+	}
+}
+
+func TestIsRelease(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"v1.0.0", true},
+		{"v1.0.0-devops", true},
+		{"v1.0.0-devops.2", true},
+		{"v1.0.0-rc.1", false},
+		// only the first identifier counts
+		{"v1.0.0-rc.devops", false},
+		{"v1.0.0-devopsx", false},
+	}
+	for _, tt := range tests {
+		if got := mustParse(t, tt.version).IsRelease([]string{"devops"}); got != tt.want {
+			t.Errorf("IsRelease(%s) = %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestAllowsPreReleases(t *testing.T) {
+	suffixes := []string{"devops"}
+	tests := []struct {
+		mode    string
+		current string
+		want    bool
+	}{
+		{PreReleaseStable, "v1.0.0-rc.1", false},
+		{PreReleaseAllow, "v1.0.0", true},
+		{PreReleaseIfPinned, "v1.0.0-rc.1", true},
+		{PreReleaseIfPinned, "v1.0.0", false},
+		// a release suffix doesn't make the current version a pre-release
+		{PreReleaseIfPinned, "v1.0.0-devops", false},
+		{PreReleaseIfPinned, "", false},
+	}
+	for _, tt := range tests {
+		var current *Version
+		if tt.current != "" {
+			current = mustParse(t, tt.current)
+		}
+		if got := AllowsPreReleases(tt.mode, current, suffixes); got != tt.want {
+			t.Errorf("AllowsPreReleases(%s, %s) = %v, want %v", tt.mode, tt.current, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+package toml
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValueKind - the type of a TOML value
+type ValueKind int
+
+const (
+	String ValueKind = iota
+	Bool
+	Array
+	InlineTable
+	// Other - numbers, dates and anything else kept only as raw text
+	Other
+)
+
+// Value - a TOML value along with its position in the source, so it can be rewritten in place
+type Value struct {
+	Kind  ValueKind
+	Str   string
+	Bool  bool
+	Items []*Value
+	Table *Table
+	Start int
+	End   int
+	quote string
+}
+
+// Table - a [table], a [[array.table]] item or an inline table; Keys keeps the source order
+type Table struct {
+	Name    string
+	IsArray bool
+	Keys    []string
+	Values  map[string]*Value
+}
+
+func newTable(name string, isArray bool) *Table {
+	return &Table{Name: name, IsArray: isArray, Values: make(map[string]*Value)}
+}
+
+// GetString - the string value of a key; false if missing or not a string
+func (t *Table) GetString(key string) (string, bool) {
+	v, ok := t.Values[key]
+	if !ok || v.Kind != String {
+		return "", false
+	}
+	return v.Str, true
+}
+
+// Document - a parsed TOML file; edits are applied on top of the original source
+type Document struct {
+	Source string
+	Root   *Table
+	Tables []*Table
+	edits  []edit
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// ArrayTables - every item of the array of tables `name`, whether written as [[name]] sections or as `name = [{...}]`
+func (d *Document) ArrayTables(name string) []*Table {
+	tables := make([]*Table, 0)
+	if v, ok := d.Root.Values[name]; ok && v.Kind == Array {
+		for _, item := range v.Items {
+			if item.Kind == InlineTable {
+				tables = append(tables, item.Table)
+			}
+		}
+	}
+	for _, t := range d.Tables {
+		if t.IsArray && t.Name == name {
+			tables = append(tables, t)
+		}
+	}
+	return tables
+}
+
+// Table - the [name] table, or nil
+func (d *Document) Table(name string) *Table {
+	for _, t := range d.Tables {
+		if !t.IsArray && t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// SetString - replace a string value, keeping its quoting style; nothing else in the file changes
+func (d *Document) SetString(v *Value, s string) {
+	quote := v.quote
+	if quote == "" {
+		quote = "\""
+	}
+	text := quote + s + quote
+	if quote == "\"" {
+		text = strconv.Quote(s)
+	}
+	d.edits = append(d.edits, edit{v.Start, v.End, text})
+	v.Kind = String
+	v.Str = s
+}
+
+// IsModified - whether any value was changed
+func (d *Document) IsModified() bool {
+	return len(d.edits) > 0
+}
+
+// String - the source with all edits applied
+func (d *Document) String() string {
+	edits := make([]edit, len(d.edits))
+	copy(edits, d.edits)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := d.Source
+	for _, e := range edits {
+		out = out[:e.start] + e.text + out[e.end:]
+	}
+	return out
+}
+
+// Parse - parse TOML source
+func Parse(src string) (*Document, error) {
+	p := &parser{src: src, doc: &Document{Source: src, Root: newTable("", false)}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.doc, nil
+}
+
+type parser struct {
+	src string
+	pos int
+	doc *Document
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.src[:p.pos], "\n") + 1
+	return fmt.Errorf("toml: line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace - skip spaces and tabs, and newlines and comments too when multiline is set
+func (p *parser) skipSpace(multiline bool) {
+	for !p.eof() {
+		c := p.peek()
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case c == '\n' && multiline:
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *parser) expectLineEnd() error {
+	p.skipSpace(false)
+	if p.eof() || p.peek() == '\n' {
+		return nil
+	}
+	return p.errorf("unexpected %q", p.peek())
+}
+
+func (p *parser) parse() error {
+	current := p.doc.Root
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			return nil
+		}
+		if p.peek() == '[' {
+			t, err := p.parseHeader()
+			if err != nil {
+				return err
+			}
+			p.doc.Tables = append(p.doc.Tables, t)
+			current = t
+		} else if err := p.parseKeyValue(current); err != nil {
+			return err
+		}
+		if err := p.expectLineEnd(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *parser) parseHeader() (*Table, error) {
+	isArray := strings.HasPrefix(p.src[p.pos:], "[[")
+	if isArray {
+		p.pos += 2
+	} else {
+		p.pos++
+	}
+	p.skipSpace(false)
+	name, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace(false)
+	closing := "]"
+	if isArray {
+		closing = "]]"
+	}
+	if !strings.HasPrefix(p.src[p.pos:], closing) {
+		return nil, p.errorf("unterminated table header %s", name)
+	}
+	p.pos += len(closing)
+	return newTable(name, isArray), nil
+}
+
+// parseKey - a bare, quoted or dotted key; dotted parts are joined with "."
+func (p *parser) parseKey() (string, error) {
+	parts := make([]string, 0)
+	for {
+		p.skipSpace(false)
+		c := p.peek()
+		if c == '"' || c == '\'' {
+			v, err := p.parseString()
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, v.Str)
+		} else {
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return "", p.errorf("expected a key")
+			}
+			parts = append(parts, p.src[start:p.pos])
+		}
+		p.skipSpace(false)
+		if p.peek() != '.' {
+			return strings.Join(parts, "."), nil
+		}
+		p.pos++
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *parser) parseKeyValue(t *Table) error {
+	key, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	p.skipSpace(false)
+	if p.peek() != '=' {
+		return p.errorf("expected = after %s", key)
+	}
+	p.pos++
+	p.skipSpace(false)
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+	if _, ok := t.Values[key]; ok {
+		return p.errorf("duplicate key %s", key)
+	}
+	t.Keys = append(t.Keys, key)
+	t.Values[key] = v
+	return nil
+}
+
+func (p *parser) parseValue() (*Value, error) {
+	switch c := p.peek(); {
+	case c == '"' || c == '\'':
+		return p.parseString()
+	case c == '[':
+		return p.parseArray()
+	case c == '{':
+		return p.parseInlineTable()
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}#\n\r", rune(p.peek())) {
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.src[start:p.pos])
+	p.pos = start + len(raw)
+	if raw == "" {
+		return nil, p.errorf("expected a value")
+	}
+	v := &Value{Kind: Other, Str: raw, Start: start, End: p.pos}
+	if raw == "true" || raw == "false" {
+		v.Kind = Bool
+		v.Bool = raw == "true"
+	}
+	return v, nil
+}
+
+func (p *parser) parseString() (*Value, error) {
+	start := p.pos
+	quote := string(p.peek())
+	if strings.HasPrefix(p.src[p.pos:], quote+quote+quote) {
+		quote = quote + quote + quote
+	}
+	p.pos += len(quote)
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			p.pos = start
+			return nil, p.errorf("unterminated string")
+		}
+		if strings.HasPrefix(p.src[p.pos:], quote) {
+			p.pos += len(quote)
+			break
+		}
+		c := p.peek()
+		if c == '\n' && len(quote) == 1 {
+			return nil, p.errorf("newline in string")
+		}
+		if c == '\\' && quote[0] == '"' {
+			r, err := p.parseEscape()
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(r)
+			continue
+		}
+		sb.WriteByte(c)
+		p.pos++
+	}
+	s := sb.String()
+	if len(quote) == 3 {
+		s = strings.TrimPrefix(s, "\n")
+	}
+	return &Value{Kind: String, Str: s, Start: start, End: p.pos, quote: quote}, nil
+}
+
+func (p *parser) parseEscape() (string, error) {
+	p.pos++
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'b':
+		return "\b", nil
+	case 't':
+		return "\t", nil
+	case 'n':
+		return "\n", nil
+	case 'f':
+		return "\f", nil
+	case 'r':
+		return "\r", nil
+	case '"':
+		return "\"", nil
+	case '\\':
+		return "\\", nil
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return "", p.errorf("invalid unicode escape")
+		}
+		n, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil {
+			return "", p.errorf("invalid unicode escape")
+		}
+		p.pos += size
+		return string(rune(n)), nil
+	case '\n':
+		// line ending backslash in a multi-line string trims the following whitespace
+		p.skipSpace(true)
+		return "", nil
+	}
+	return "", p.errorf("invalid escape \\%c", c)
+}
+
+func (p *parser) parseArray() (*Value, error) {
+	v := &Value{Kind: Array, Start: p.pos}
+	p.pos++
+	for {
+		p.skipSpace(true)
+		if p.peek() == ']' {
+			p.pos++
+			v.End = p.pos
+			return v, nil
+		}
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		v.Items = append(v.Items, item)
+		p.skipSpace(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *parser) parseInlineTable() (*Value, error) {
+	v := &Value{Kind: InlineTable, Start: p.pos, Table: newTable("", false)}
+	p.pos++
+	for {
+		p.skipSpace(false)
+		if p.peek() == '}' {
+			p.pos++
+			v.End = p.pos
+			return v, nil
+		}
+		if err := p.parseKeyValue(v.Table); err != nil {
+			return nil, err
+		}
+		p.skipSpace(false)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("expected , or } in inline table")
+		}
+	}
+}
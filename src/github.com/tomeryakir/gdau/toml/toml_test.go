@@ -0,0 +1,160 @@
+package toml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testGopkg = `# Gopkg.toml example
+required = ["github.com/a/cmd", 'github.com/b/cmd'] # tools
+
+[prune]
+  go-tests = true
+  unused-packages = true
+
+[[constraint]]
+  name = "github.com/foo/bar" # the main dependency
+  version = "^1.2.0"
+
+[[constraint]]
+  "name" = 'github.com/foo/baz'
+  branch = "develop"
+
+[[override]]
+  name = "github.com/foo/qux"
+  source = "https://github.com/fork/qux.git"
+  revision = "0123456789abcdef0123456789abcdef01234567"
+
+[metadata."quoted.key"]
+  "a b" = "c # not a comment"
+  numbers = [
+    1,
+    2, # two
+  ]
+
+projects = [{ name = "github.com/inline/one", version = "v1.0.0" }, { name = 'github.com/inline/two', revision = "abc" }]
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(testGopkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	required := doc.Root.Values["required"]
+	if required == nil || required.Kind != Array || len(required.Items) != 2 || required.Items[0].Str != "github.com/a/cmd" || required.Items[1].Str != "github.com/b/cmd" {
+		t.Errorf("required = %+v, want the two commands", required)
+	}
+	if prune := doc.Table("prune"); prune == nil || !prune.Values["go-tests"].Bool || !reflect.DeepEqual(prune.Keys, []string{"go-tests", "unused-packages"}) {
+		t.Errorf("prune = %+v", prune)
+	}
+
+	tests := []struct {
+		table string
+		index int
+		key   string
+		want  string
+	}{
+		{"constraint", 0, "name", "github.com/foo/bar"},
+		{"constraint", 0, "version", "^1.2.0"},
+		{"constraint", 1, "name", "github.com/foo/baz"},
+		{"constraint", 1, "branch", "develop"},
+		{"override", 0, "source", "https://github.com/fork/qux.git"},
+	}
+	for _, tt := range tests {
+		tables := doc.ArrayTables(tt.table)
+		if len(tables) <= tt.index {
+			t.Errorf("%s has %d tables", tt.table, len(tables))
+			continue
+		}
+		if got, ok := tables[tt.index].GetString(tt.key); !ok || got != tt.want {
+			t.Errorf("%s[%d].%s = %q, %v, want %q", tt.table, tt.index, tt.key, got, ok, tt.want)
+		}
+	}
+
+	metadata := doc.Table("metadata.quoted.key")
+	if metadata == nil {
+		t.Fatalf("the quoted table name wasn't parsed: %+v", doc.Tables)
+	}
+	if got, _ := metadata.GetString("a b"); got != "c # not a comment" {
+		t.Errorf(`"a b" = %q, want the # kept inside the string`, got)
+	}
+	if numbers := metadata.Values["numbers"]; numbers == nil || len(numbers.Items) != 2 {
+		t.Errorf("numbers = %+v, want a multi-line array of 2 items", numbers)
+	}
+	// after a [table] header, the key belongs to that table
+	projects := metadata.Values["projects"]
+	if projects == nil || len(projects.Items) != 2 || projects.Items[1].Kind != InlineTable {
+		t.Fatalf("projects = %+v, want an array of 2 inline tables", projects)
+	}
+	if got, _ := projects.Items[1].Table.GetString("name"); got != "github.com/inline/two" {
+		t.Errorf("projects[1].name = %q", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"name = \"unterminated\n",
+		"[table\nkey = 1\n",
+		"key = 1\nkey = 2\n",
+		"key 1\n",
+		"key = \"a\" \"b\"\n",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestSetString(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		key   string
+		value string
+		want  string
+	}{
+		{"double quotes", "# top\nversion = \"v1.0.0\" # pinned\nname = \"x\"\n", "version", "v1.2.0", "# top\nversion = \"v1.2.0\" # pinned\nname = \"x\"\n"},
+		{"single quotes", "version   =   'v1.0.0'\n", "version", "v2.0.0", "version   =   'v2.0.0'\n"},
+		{"escaped", "version = \"v1\"\n", "version", `a"b`, "version = \"a\\\"b\"\n"},
+		{"longer value", "a = \"1\"\nb = \"2\"\n", "a", "1234567", "a = \"1234567\"\nb = \"2\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.IsModified() {
+				t.Errorf("a parsed document is modified")
+			}
+			doc.SetString(doc.Root.Values[tt.key], tt.value)
+			if !doc.IsModified() || doc.String() != tt.want {
+				t.Errorf("got %q, want %q", doc.String(), tt.want)
+			}
+			reparsed, err := Parse(doc.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := reparsed.Root.GetString(tt.key); got != tt.value {
+				t.Errorf("reparsed %s = %q, want %q", tt.key, got, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetStringKeepsTheRest(t *testing.T) {
+	doc, err := Parse(testGopkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// edits in any order are applied from the end, so earlier offsets stay valid
+	bar, qux := doc.ArrayTables("constraint")[0], doc.ArrayTables("override")[0]
+	doc.SetString(qux.Values["revision"], "fedcba9876543210fedcba9876543210fedcba98")
+	doc.SetString(bar.Values["version"], "^1.3.0")
+	want := strings.Replace(testGopkg, `version = "^1.2.0"`, `version = "^1.3.0"`, 1)
+	want = strings.Replace(want, "0123456789abcdef0123456789abcdef01234567", "fedcba9876543210fedcba9876543210fedcba98", 1)
+	if got := doc.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -1,13 +1,18 @@
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
+// Logger - prints whole lines; safe to use from several goroutines
 type Logger struct {
 	debug bool
+	mu    sync.Mutex
 }
 
 func NewLogger(debug bool) *Logger {
-	return &Logger{debug}
+	return &Logger{debug: debug}
 }
 
 func (l *Logger) PanicWithMessage(msgFormat string, vars ...interface{}) {
@@ -15,11 +20,17 @@ func (l *Logger) PanicWithMessage(msgFormat string, vars ...interface{}) {
 }
 
 func (l *Logger) LogInfo(msgFormat string, vars ...interface{}) {
-	fmt.Println(fmt.Sprintf(msgFormat, vars...))
+	l.println(fmt.Sprintf(msgFormat, vars...))
 }
 
 func (l *Logger) LogDebug(msgFormat string, vars ...interface{}) {
 	if l.debug {
-		fmt.Println(fmt.Sprintf(msgFormat, vars...))
+		l.println(fmt.Sprintf(msgFormat, vars...))
 	}
 }
+
+func (l *Logger) println(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Println(msg)
+}
@@ -0,0 +1,235 @@
+package verify
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	git "github.com/tomeryakir/gdau/gitutils"
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// verification modes
+const (
+	Build = "build"
+	Test  = "test"
+)
+
+// MaxOutputLines - how much of the go command output is kept, from the end
+const MaxOutputLines = 30
+
+// Modes - the accepted verification modes
+func Modes() []string {
+	return []string{Build, Test}
+}
+
+// IsMode - whether a verification mode is supported
+func IsMode(mode string) bool {
+	return mode == Build || mode == Test
+}
+
+// Workspace - a throwaway copy of the project, built against dependencies exported next to it from their mirrors
+// (or, for go modules, against the versions its go.mod names)
+type Workspace struct {
+	root     string
+	project  string
+	original string
+	// the user's GOPATH, if any; read for the packages that weren't exported, never written
+	gopath string
+	module bool
+	// import path -> the revision exported there
+	exported map[string]string
+	logger   *utils.Logger
+}
+
+// New - copy the project at gitRoot into a GOPATH of its own, at importPath.
+// The vendored copies of the unvendor repositories are left out, so they're built from the exported dependencies.
+func New(gitRoot, importPath, gopath string, module bool, unvendor []string, logger *utils.Logger) (*Workspace, error) {
+	absGopath := ""
+	if gopath != "" {
+		var err error
+		if absGopath, err = filepath.Abs(gopath); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s. error: %v", gopath, err)
+		}
+	}
+	root, err := ioutil.TempDir("", "gdau-verify-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a verification workspace. error: %v", err)
+	}
+	w := &Workspace{
+		root:     root,
+		project:  filepath.Join(root, "src", filepath.FromSlash(importPath)),
+		original: gitRoot,
+		gopath:   absGopath,
+		module:   module,
+		exported: make(map[string]string),
+		logger:   logger,
+	}
+	if err := w.copyProject(unvendor); err != nil {
+		os.RemoveAll(root)
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *Workspace) copyProject(unvendor []string) error {
+	skip := map[string]bool{w.gopath: true}
+	err := filepath.Walk(w.original, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(w.original, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(w.project, rel)
+		if abs, err := filepath.Abs(p); err == nil && skip[abs] {
+			return filepath.SkipDir
+		}
+		switch {
+		case info.IsDir():
+			if info.Name() == ".git" || isVendored(rel, unvendor) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			return copyFile(p, target, info.Mode())
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s. error: %v", w.original, w.project, err)
+	}
+	return nil
+}
+
+// isVendored - whether a directory of the project is the vendored copy of one of the repositories
+func isVendored(rel string, repos []string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, repo := range repos {
+		if rel == "vendor/"+repo || strings.HasSuffix(rel, "/vendor/"+repo) {
+			return true
+		}
+	}
+	return false
+}
+
+func copyFile(from, to string, mode os.FileMode) error {
+	contents, err := ioutil.ReadFile(from)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(to, contents, mode.Perm())
+}
+
+// Path - where a file or directory of the project is in the copy
+func (w *Workspace) Path(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s. error: %v", p, err)
+	}
+	rel, err := filepath.Rel(w.original, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s isn't in %s", p, w.original)
+	}
+	return filepath.Join(w.project, rel), nil
+}
+
+// Reset - put back the original files of a project directory, undoing the updates applied to the copy
+func (w *Workspace) Reset(dir string) error {
+	target, err := w.Path(dir)
+	if err != nil {
+		return err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %s. error: %v", dir, err)
+	}
+	for _, info := range infos {
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFile(filepath.Join(dir, info.Name()), filepath.Join(target, info.Name()), info.Mode()); err != nil {
+			return fmt.Errorf("failed to reset %s. error: %v", filepath.Join(target, info.Name()), err)
+		}
+	}
+	return nil
+}
+
+// Checkout - export a revision of a dependency from its repository (a mirror) into the workspace GOPATH, at its import path.
+// Module projects don't build from the GOPATH, so there's nothing to export.
+func (w *Workspace) Checkout(importPath, repoDir, rev string) error {
+	if w.module || w.exported[importPath] == rev {
+		return nil
+	}
+	if err := git.Export(repoDir, rev, filepath.Join(w.root, "src", filepath.FromSlash(importPath)), w.logger); err != nil {
+		delete(w.exported, importPath)
+		return err
+	}
+	w.exported[importPath] = rev
+	return nil
+}
+
+// Run - go build, and go test for the test mode, in a directory of the project.
+// Returns whether it passed and the end of the output; the error is for a go command that couldn't run at all.
+func (w *Workspace) Run(dir, mode string) (bool, string, error) {
+	target, err := w.Path(dir)
+	if err != nil {
+		return false, "", err
+	}
+	commands := [][]string{{"build", "./..."}}
+	if mode == Test {
+		commands = append(commands, []string{"test", "./..."})
+	}
+	for _, args := range commands {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = target
+		cmd.Env = append(os.Environ(), w.env()...)
+		w.logger.LogDebug("running command %v", *cmd)
+		out, err := cmd.CombinedOutput()
+		w.logger.LogDebug("got output %s", string(out))
+		if err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				return false, "", fmt.Errorf("failed to run go %s in %s. error: %v", strings.Join(args, " "), target, err)
+			}
+			return false, fmt.Sprintf("go %s\n%s", strings.Join(args, " "), tail(string(out))), nil
+		}
+	}
+	return true, "", nil
+}
+
+func (w *Workspace) env() []string {
+	if w.module {
+		return []string{"GO111MODULE=on", "GOFLAGS=-mod=mod"}
+	}
+	gopath := w.root
+	if w.gopath != "" {
+		gopath += string(os.PathListSeparator) + w.gopath
+	}
+	return []string{"GO111MODULE=off", "GOFLAGS=", "GOPATH=" + gopath}
+}
+
+// tail - the last MaxOutputLines lines of an output
+func tail(out string) string {
+	lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	if len(lines) > MaxOutputLines {
+		lines = append([]string{"..."}, lines[len(lines)-MaxOutputLines:]...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Close - remove the copy and the exported dependencies
+func (w *Workspace) Close() {
+	if err := os.RemoveAll(w.root); err != nil {
+		w.logger.LogInfo("failed to remove %s. error: %v", w.root, err)
+	}
+}
@@ -0,0 +1,133 @@
+package verify
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tomeryakir/gdau/utils"
+)
+
+// writeFiles - write files (name -> contents) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, contents := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// commit - commit the files of dir with the git command line, and return the commit
+func commit(t *testing.T, dir string, files map[string]string) string {
+	t.Helper()
+	writeFiles(t, dir, files)
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "commit"}, {"rev-parse", "HEAD"}} {
+		cmd := exec.Command("git", append([]string{"-c", "commit.gpgsign=false"}, args...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_COMMITTER_NAME=a", "GIT_COMMITTER_EMAIL=a@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		if args[0] == "rev-parse" {
+			return strings.TrimSpace(string(out))
+		}
+	}
+	return ""
+}
+
+func TestWorkspace(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go isn't installed")
+	}
+	tmp, err := ioutil.TempDir("", "gdau-verify-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	logger := utils.NewLogger(false)
+
+	// the dependency renames Old to New in its second commit
+	depRepo := filepath.Join(tmp, "dep")
+	oldRev := commit(t, depRepo, map[string]string{"dep.go": "package dep\n\nfunc Old() {}\n"})
+	os.Remove(filepath.Join(depRepo, "dep.go"))
+	newRev := commit(t, depRepo, map[string]string{"dep.go": "package dep\n\nfunc New() {}\n"})
+
+	// the project already calls New, its vendored copy of the dependency only has Old
+	project := filepath.Join(tmp, "app")
+	writeFiles(t, project, map[string]string{
+		"main.go":                       "package main\n\nimport \"example.com/dep\"\n\nfunc main() { dep.New() }\n",
+		"vendor/example.com/dep/dep.go": "package dep\n\nfunc Old() {}\n",
+		".git/HEAD":                     "ref: refs/heads/master\n",
+	})
+	ws, err := New(project, "example.com/app", "", false, []string{"example.com/dep"}, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	copyMain, err := ws.Path(filepath.Join(project, "main.go"))
+	if err != nil || !utils.DirExists(copyMain) || !strings.HasSuffix(filepath.ToSlash(copyMain), "/src/example.com/app/main.go") {
+		t.Fatalf("Path(main.go) = %s, %v", copyMain, err)
+	}
+	for _, left := range []string{".git", "vendor/example.com/dep"} {
+		if p, _ := ws.Path(filepath.Join(project, left)); utils.DirExists(p) {
+			t.Errorf("%s was copied into the workspace", left)
+		}
+	}
+	if _, err := ws.Path(tmp); err == nil {
+		t.Errorf("Path of a directory outside the project succeeded")
+	}
+
+	if err := ws.Checkout("example.com/dep", depRepo, oldRev); err != nil {
+		t.Fatal(err)
+	}
+	passed, out, err := ws.Run(project, Build)
+	if err != nil || passed || !strings.HasPrefix(out, "go build") || !strings.Contains(out, "New") {
+		t.Errorf("building against the old revision = %v, %q, %v, want a failure about New", passed, out, err)
+	}
+	if err := ws.Checkout("example.com/dep", depRepo, newRev); err != nil {
+		t.Fatal(err)
+	}
+	if passed, out, err := ws.Run(project, Test); err != nil || !passed {
+		t.Errorf("building against the new revision = %v, %q, %v, want it to pass", passed, out, err)
+	}
+
+	// an update written to the copy is undone
+	if err := ioutil.WriteFile(copyMain, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Reset(project); err != nil {
+		t.Fatal(err)
+	}
+	if contents, _ := ioutil.ReadFile(copyMain); !strings.Contains(string(contents), "dep.New()") {
+		t.Errorf("Reset left %q", contents)
+	}
+
+	root := ws.root
+	ws.Close()
+	if utils.DirExists(root) {
+		t.Errorf("Close left %s", root)
+	}
+}
+
+func TestTail(t *testing.T) {
+	lines := make([]string, 0, MaxOutputLines+5)
+	for i := 0; i < MaxOutputLines+5; i++ {
+		lines = append(lines, "line")
+	}
+	if got := strings.Split(tail(strings.Join(lines, "\n")+"\n"), "\n"); len(got) != MaxOutputLines+1 || got[0] != "..." {
+		t.Errorf("tail kept %d lines starting with %q", len(got), got[0])
+	}
+	if got := tail("short\n"); got != "short" {
+		t.Errorf("tail(short) = %q", got)
+	}
+}
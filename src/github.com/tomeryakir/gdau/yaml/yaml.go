@@ -0,0 +1,415 @@
+package yaml
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind - the type of a YAML node
+type Kind int
+
+const (
+	Scalar Kind = iota
+	Mapping
+	Sequence
+)
+
+// Node - a YAML node; scalars keep their position in the source so they can be rewritten in place.
+// This covers the block style used by dependency and config files, plus flow sequences of scalars.
+type Node struct {
+	Kind   Kind
+	Value  string
+	Keys   []string
+	Values map[string]*Node
+	Items  []*Node
+	Start  int
+	End    int
+	Line   int
+	quote  byte
+	// the line of each mapping key, a block value starts on the line after it
+	keyLines map[string]int
+}
+
+// Get - the value of a mapping key, or nil
+func (n *Node) Get(key string) *Node {
+	if n == nil || n.Kind != Mapping {
+		return nil
+	}
+	return n.Values[key]
+}
+
+// KeyLine - the line a mapping key is on, or 0
+func (n *Node) KeyLine(key string) int {
+	if n == nil || n.Kind != Mapping {
+		return 0
+	}
+	return n.keyLines[key]
+}
+
+// String - the scalar value of a mapping key, or an empty string
+func (n *Node) String(key string) string {
+	v := n.Get(key)
+	if v == nil || v.Kind != Scalar {
+		return ""
+	}
+	return v.Value
+}
+
+// List - the items of a sequence under a mapping key; a single scalar is returned as a one item list
+func (n *Node) List(key string) []*Node {
+	v := n.Get(key)
+	if v == nil {
+		return nil
+	}
+	if v.Kind == Scalar {
+		if v.Value == "" {
+			return nil
+		}
+		return []*Node{v}
+	}
+	return v.Items
+}
+
+// Strings - the scalar items under a mapping key
+func (n *Node) Strings(key string) []string {
+	values := make([]string, 0)
+	for _, item := range n.List(key) {
+		if item.Kind == Scalar {
+			values = append(values, item.Value)
+		}
+	}
+	return values
+}
+
+// Document - a parsed YAML file; edits are applied on top of the original source
+type Document struct {
+	Source string
+	Root   *Node
+	edits  []edit
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+// SetScalar - replace the value of a scalar node, keeping its quoting; nothing else in the file changes
+func (d *Document) SetScalar(n *Node, value string) {
+	text := value
+	switch n.quote {
+	case '"':
+		text = strconv.Quote(value)
+	case '\'':
+		text = "'" + strings.Replace(value, "'", "''", -1) + "'"
+	}
+	if n.Start == n.End {
+		// an empty value right after "key:" or "-"
+		text = " " + text
+	}
+	d.edits = append(d.edits, edit{n.Start, n.End, text})
+	n.Value = value
+}
+
+// IsModified - whether any value was changed
+func (d *Document) IsModified() bool {
+	return len(d.edits) > 0
+}
+
+// String - the source with all edits applied
+func (d *Document) String() string {
+	edits := make([]edit, len(d.edits))
+	copy(edits, d.edits)
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := d.Source
+	for _, e := range edits {
+		out = out[:e.start] + e.text + out[e.end:]
+	}
+	return out
+}
+
+// line - a meaningful source line; indent and text change when a sequence item holds a mapping
+type line struct {
+	number int
+	indent int
+	text   string
+	offset int
+}
+
+func (l *line) isSeqItem() bool {
+	return l.text == "-" || strings.HasPrefix(l.text, "- ")
+}
+
+// Parse - parse YAML source
+func Parse(src string) (*Document, error) {
+	p := &parser{src: src}
+	offset := 0
+	for i, raw := range strings.Split(src, "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		text := strings.TrimRight(stripComment(trimmed), " \t\r")
+		if text != "" && text != "---" && text != "..." && !strings.HasPrefix(text, "%") {
+			p.lines = append(p.lines, &line{i + 1, len(raw) - len(trimmed), text, offset + len(raw) - len(trimmed)})
+		}
+		offset += len(raw) + 1
+	}
+	root := &Node{Kind: Mapping, Values: make(map[string]*Node)}
+	if len(p.lines) > 0 {
+		var err error
+		if root, err = p.parseBlock(p.lines[0].indent); err != nil {
+			return nil, err
+		}
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf(p.lines[p.pos], "unexpected indentation")
+	}
+	return &Document{Source: src, Root: root}, nil
+}
+
+// stripComment - drop a trailing comment that is outside of quotes
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			// an escaped character
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			// '' is a quote inside single quotes
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '[' || s[i-1] == ',' || s[i-1] == '-' || s[i-1] == ':' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+type parser struct {
+	src   string
+	lines []*line
+	pos   int
+}
+
+func (p *parser) errorf(l *line, format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", l.number, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) current() *line {
+	if p.pos >= len(p.lines) {
+		return nil
+	}
+	return p.lines[p.pos]
+}
+
+func (p *parser) parseBlock(indent int) (*Node, error) {
+	if l := p.current(); l != nil && l.isSeqItem() {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func (p *parser) parseMapping(indent int) (*Node, error) {
+	n := &Node{Kind: Mapping, Values: make(map[string]*Node), keyLines: make(map[string]int)}
+	if l := p.current(); l != nil {
+		n.Line = l.number
+	}
+	for l := p.current(); l != nil && l.indent == indent && !l.isSeqItem(); l = p.current() {
+		key, rest, restOffset, ok := splitKey(l.text)
+		if !ok {
+			return nil, p.errorf(l, "expected a key: %s", l.text)
+		}
+		p.pos++
+		var value *Node
+		var err error
+		next := p.current()
+		switch {
+		case rest != "":
+			value, err = p.parseInline(l, rest, l.offset+restOffset)
+		case next != nil && next.indent > indent:
+			value, err = p.parseBlock(next.indent)
+		case next != nil && next.indent == indent && next.isSeqItem():
+			// a sequence may sit at the same indentation as its key
+			value, err = p.parseSequence(indent)
+		default:
+			value = &Node{Kind: Scalar, Start: l.offset + len(l.text), End: l.offset + len(l.text), Line: l.number}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := n.Values[key]; ok {
+			return nil, p.errorf(l, "duplicate key %s", key)
+		}
+		n.Keys = append(n.Keys, key)
+		n.Values[key] = value
+		n.keyLines[key] = l.number
+	}
+	return n, nil
+}
+
+func (p *parser) parseSequence(indent int) (*Node, error) {
+	n := &Node{Kind: Sequence}
+	if l := p.current(); l != nil {
+		n.Line = l.number
+	}
+	for l := p.current(); l != nil && l.indent == indent && l.isSeqItem(); l = p.current() {
+		rest := strings.TrimLeft(strings.TrimPrefix(l.text, "-"), " ")
+		restCol := l.indent + len(l.text) - len(rest)
+		var item *Node
+		var err error
+		if rest == "" {
+			p.pos++
+			if next := p.current(); next != nil && next.indent > indent {
+				item, err = p.parseBlock(next.indent)
+			} else {
+				item = &Node{Kind: Scalar, Start: l.offset + 1, End: l.offset + 1, Line: l.number}
+			}
+		} else if _, _, _, ok := splitKey(rest); ok || strings.HasPrefix(rest, "- ") {
+			// "- key: value" starts a mapping (or a nested sequence) at the column of its first key
+			l.offset += restCol - l.indent
+			l.indent = restCol
+			l.text = rest
+			item, err = p.parseBlock(restCol)
+		} else {
+			p.pos++
+			item, err = p.parseInline(l, rest, l.offset+restCol-l.indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		n.Items = append(n.Items, item)
+	}
+	return n, nil
+}
+
+// splitKey - split `key: value`; restOffset is where the value starts in s
+func splitKey(s string) (string, string, int, bool) {
+	idx := -1
+	if s[0] == '"' || s[0] == '\'' {
+		end := strings.IndexByte(s[1:], s[0])
+		if end < 0 {
+			return "", "", 0, false
+		}
+		if rest := s[end+2:]; strings.HasPrefix(rest, ":") {
+			idx = end + 2
+		}
+	} else if i := strings.Index(s, ": "); i >= 0 {
+		idx = i
+	} else if strings.HasSuffix(s, ":") {
+		idx = len(s) - 1
+	}
+	if idx <= 0 || strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") {
+		return "", "", 0, false
+	}
+	key := unquote(strings.TrimSpace(s[:idx]))
+	rest := strings.TrimLeft(s[idx+1:], " ")
+	return key, rest, len(s) - len(rest), true
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// parseInline - a value written on the same line as its key or dash
+func (p *parser) parseInline(l *line, text string, offset int) (*Node, error) {
+	if text == "|" || text == ">" || strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">") {
+		return p.parseBlockScalar(l, text, offset), nil
+	}
+	if strings.HasPrefix(text, "[") {
+		return p.parseFlowSequence(l, text, offset)
+	}
+	return parseScalar(l, text, offset)
+}
+
+func parseScalar(l *line, text string, offset int) (*Node, error) {
+	n := &Node{Kind: Scalar, Start: offset, End: offset + len(text), Line: l.number}
+	switch text[0] {
+	case '"':
+		v, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("yaml: line %d: invalid string %s", l.number, text)
+		}
+		n.Value = v
+		n.quote = '"'
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, fmt.Errorf("yaml: line %d: invalid string %s", l.number, text)
+		}
+		n.Value = strings.Replace(text[1:len(text)-1], "''", "'", -1)
+		n.quote = '\''
+	default:
+		n.Value = text
+		if text == "~" || text == "null" {
+			n.Value = ""
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) parseFlowSequence(l *line, text string, offset int) (*Node, error) {
+	if !strings.HasSuffix(text, "]") {
+		return nil, p.errorf(l, "multi-line flow sequences aren't supported")
+	}
+	n := &Node{Kind: Sequence, Line: l.number, Start: offset, End: offset + len(text)}
+	inner := text[1 : len(text)-1]
+	pos := 1
+	for _, raw := range strings.Split(inner, ",") {
+		item := strings.TrimSpace(raw)
+		start := pos + strings.Index(raw, item)
+		pos += len(raw) + 1
+		if item == "" {
+			continue
+		}
+		v, err := parseScalar(l, item, offset+start)
+		if err != nil {
+			return nil, err
+		}
+		n.Items = append(n.Items, v)
+	}
+	return n, nil
+}
+
+// parseBlockScalar - a literal (|) or folded (>) block; the lines are kept verbatim, joined by newlines
+func (p *parser) parseBlockScalar(l *line, text string, offset int) *Node {
+	n := &Node{Kind: Scalar, Line: l.number, Start: offset, End: offset + len(text)}
+	// read from the source, where the comments and blank lines of the text are still there
+	raw := strings.Split(p.src, "\n")
+	parts := make([]string, 0)
+	indent := -1
+	end := l.number
+	for ; end < len(raw); end++ {
+		text := strings.TrimRight(raw[end], " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" {
+			parts = append(parts, "")
+			continue
+		}
+		lineIndent := len(text) - len(trimmed)
+		if indent < 0 {
+			indent = lineIndent
+		}
+		if lineIndent <= l.indent || lineIndent < indent {
+			break
+		}
+		parts = append(parts, text[indent:])
+	}
+	for len(parts) > 0 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	for next := p.current(); next != nil && next.number <= end; next = p.current() {
+		p.pos++
+	}
+	n.Value = strings.Join(parts, "\n")
+	return n
+}
@@ -0,0 +1,161 @@
+package yaml
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testGlide = `# glide.yaml
+package: example.com/app
+"quoted key": 'it''s # not a comment'
+import:
+- package: github.com/foo/bar   # the main dependency
+  version: ^1.2.0
+  subpackages: [a, "b", 'c']
+- package: github.com/foo/baz
+  version:
+  repo: git@github.com:fork/baz.git
+testImport:
+  - package: github.com/foo/test
+    subpackages:
+      - assert
+      - require
+description: |
+  a block scalar
+  # kept as text
+owners: ~
+`
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(testGlide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Root
+	if want := []string{"package", "quoted key", "import", "testImport", "description", "owners"}; !reflect.DeepEqual(root.Keys, want) {
+		t.Errorf("keys = %v, want %v", root.Keys, want)
+	}
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"plain", root.String("package"), "example.com/app"},
+		{"quoted key and single quotes", root.String("quoted key"), "it's # not a comment"},
+		{"trailing comment", root.List("import")[0].String("package"), "github.com/foo/bar"},
+		{"range", root.List("import")[0].String("version"), "^1.2.0"},
+		{"empty", root.List("import")[1].String("version"), ""},
+		{"colon in a value", root.List("import")[1].String("repo"), "git@github.com:fork/baz.git"},
+		{"indented sequence", root.List("testImport")[0].String("package"), "github.com/foo/test"},
+		{"null", root.String("owners"), ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if got := root.List("import")[0].Strings("subpackages"); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("flow sequence = %v", got)
+	}
+	if got := root.List("testImport")[0].Strings("subpackages"); !reflect.DeepEqual(got, []string{"assert", "require"}) {
+		t.Errorf("block sequence = %v", got)
+	}
+	if got := root.String("description"); got != "a block scalar\n# kept as text" {
+		t.Errorf("block scalar = %q", got)
+	}
+	if line := root.List("import")[1].KeyLine("repo"); line != 10 {
+		t.Errorf("KeyLine(repo) = %d, want 10", line)
+	}
+	if line := root.KeyLine("testImport"); line != 11 {
+		t.Errorf("KeyLine(testImport) = %d, want 11", line)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"a: 1\na: 2\n",
+		"a: 1\n  b: 2\n",
+		"a: \"unterminated\n",
+		"a: [1, 2\n",
+		"- a\nb: 1\n",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestSetScalar(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		value string
+		want  string
+	}{
+		{"plain", "version: v1.0.0 # pinned\nname: x\n", "v1.2.0", "version: v1.2.0 # pinned\nname: x\n"},
+		{"double quotes", "version: \"v1.0.0\"\n", "v2.0.0", "version: \"v2.0.0\"\n"},
+		{"single quotes", "version: 'v1.0.0'\n", "it's", "version: 'it''s'\n"},
+		{"empty", "version:\nname: x\n", "v1.0.0", "version: v1.0.0\nname: x\n"},
+		{"longer value", "version: v1\nname: x\n", "0123456789abcdef", "version: 0123456789abcdef\nname: x\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.IsModified() {
+				t.Errorf("a parsed document is modified")
+			}
+			doc.SetScalar(doc.Root.Get("version"), tt.value)
+			if !doc.IsModified() || doc.String() != tt.want {
+				t.Errorf("got %q, want %q", doc.String(), tt.want)
+			}
+			reparsed, err := Parse(doc.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reparsed.Root.String("version"); got != tt.value {
+				t.Errorf("reparsed version = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetScalarKeepsTheRest(t *testing.T) {
+	doc, err := Parse(testGlide)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imports := doc.Root.List("import")
+	// edits in any order are applied from the end, so earlier offsets stay valid
+	doc.SetScalar(imports[1].Get("version"), "v0.3.0")
+	doc.SetScalar(imports[0].Get("version"), "^1.3.0")
+	want := strings.Replace(testGlide, "version: ^1.2.0", "version: ^1.3.0", 1)
+	want = strings.Replace(want, "  version:\n", "  version: v0.3.0\n", 1)
+	if got := doc.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"a: b # comment", "a: b "},
+		{"a: b#c", "a: b#c"},
+		{"# comment", ""},
+		{`a: "b # c"`, `a: "b # c"`},
+		{`a: "b \" # c" # comment`, `a: "b \" # c" `},
+		{"a: 'it''s # c' # comment", "a: 'it''s # c' "},
+		{"- 'b # c'", "- 'b # c'"},
+		{"a: don't # comment", "a: don't "},
+	}
+	for _, tt := range tests {
+		if got := stripComment(tt.line); got != tt.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}